@@ -0,0 +1,158 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// AdminOption configures the handler returned by AdminHandler
+type AdminOption func(a *admin)
+
+// AdminAuth protects all write actions of the admin API with the given middleware,
+// which must only call the next handler for authorized requests
+func AdminAuth(middleware func(next http.Handler) http.Handler) AdminOption {
+	return func(a *admin) {
+		a.auth = middleware
+	}
+}
+
+type admin struct {
+	c    *Container
+	auth func(next http.Handler) http.Handler
+}
+
+// AdminHandler returns a JSON API to inspect and control the services of the container:
+//
+//	GET  /services                 status of all services, see Container.Status
+//	GET  /services/{name}          status of a single service
+//	POST /services/{name}/stop     stops a single service, see Container.StopService
+//	POST /services/{name}/restart  restarts a single service, see Container.RestartService
+//	POST /shutdown                 stops all services, see Container.StopAll
+//	GET  /events                   streams lifecycle events as server-sent events, see Container.Subscribe
+//
+// Use http.StripPrefix to serve it below a path.
+func AdminHandler(c *Container, opts ...AdminOption) http.Handler {
+	a := &admin{
+		c: c,
+		auth: func(next http.Handler) http.Handler {
+			return next
+		},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /services", a.listServices)
+	mux.HandleFunc("GET /services/{name}", a.getService)
+	mux.Handle("POST /services/{name}/stop", a.auth(http.HandlerFunc(a.stopService)))
+	mux.Handle("POST /services/{name}/restart", a.auth(http.HandlerFunc(a.restartService)))
+	mux.Handle("POST /shutdown", a.auth(http.HandlerFunc(a.shutdown)))
+	mux.HandleFunc("GET /events", a.events)
+	return mux
+}
+
+func (a *admin) listServices(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.c.Status())
+}
+
+func (a *admin) getService(w http.ResponseWriter, r *http.Request) {
+	a.writeStatus(w, r.PathValue("name"))
+}
+
+func (a *admin) stopService(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !a.registered(w, name) {
+		return
+	}
+	if err := a.c.StopService(r.Context(), name); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	a.writeStatus(w, name)
+}
+
+func (a *admin) restartService(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !a.registered(w, name) {
+		return
+	}
+	if err := a.c.RestartService(r.Context(), name); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	a.writeStatus(w, name)
+}
+
+func (a *admin) shutdown(w http.ResponseWriter, r *http.Request) {
+	a.c.mu.Lock()
+	started := a.c.runCtxCancel != nil
+	a.c.mu.Unlock()
+	if !started {
+		writeError(w, http.StatusConflict, fmt.Errorf("container not started"))
+		return
+	}
+	a.c.logger().Info("Shutdown requested via admin API", "remote", r.RemoteAddr)
+	a.c.StopAll()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (a *admin) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+	events, cancel := a.c.Subscribe(64)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case e := <-events:
+			data, err := json.Marshal(e)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// registered writes a 404 response when the service is not registered
+func (a *admin) registered(w http.ResponseWriter, name string) bool {
+	if _, ok := a.c.ServiceState(name); !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("service '%s' not registered", name))
+		return false
+	}
+	return true
+}
+
+func (a *admin) writeStatus(w http.ResponseWriter, name string) {
+	for _, s := range a.c.Status() {
+		if s.Name == name {
+			writeJSON(w, http.StatusOK, s)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Errorf("service '%s' not registered", name))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
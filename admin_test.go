@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func adminRequest(h http.Handler, method string, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func TestAdminHandler(t *testing.T) {
+	c := service.NewContainer()
+	c.Register(blockingService("db"))
+	c.Register(blockingService("api"), service.DependsOn("db"))
+	h := service.AdminHandler(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+
+	rec := adminRequest(h, "GET", "/services")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var status []map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	require.Len(t, status, 2)
+	assert.Equal(t, "db", status[0]["name"])
+
+	rec = adminRequest(h, "GET", "/services/unknown")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"error":"service 'unknown' not registered"}`, rec.Body.String())
+
+	rec = adminRequest(h, "POST", "/services/db/stop")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.JSONEq(t, `{"error":"service 'db' is required by running service 'api'"}`, rec.Body.String())
+
+	rec = adminRequest(h, "POST", "/services/api/stop")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"state":"stopped"`)
+
+	rec = adminRequest(h, "POST", "/services/api/restart")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"state":"running"`)
+
+	rec = adminRequest(h, "GET", "/services/api/stop")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = adminRequest(h, "POST", "/shutdown")
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	c.WaitAllStopped()
+	assert.Equal(t, 0, c.RunningCount())
+}
+
+func TestAdminAuth(t *testing.T) {
+	c := service.NewContainer()
+	c.Register(blockingService("db"))
+	h := service.AdminHandler(c, service.AdminAuth(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}))
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	defer c.WaitAllStopped()
+	defer c.StopAll()
+
+	assert.Equal(t, http.StatusOK, adminRequest(h, "GET", "/services").Code)
+	assert.Equal(t, http.StatusUnauthorized, adminRequest(h, "POST", "/shutdown").Code)
+	assert.Equal(t, http.StatusUnauthorized, adminRequest(h, "POST", "/services/db/stop").Code)
+	assert.Equal(t, 1, c.RunningCount())
+
+	req := httptest.NewRequest("POST", "/services/db/stop", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 0, c.RunningCount())
+}
+
+func TestAdminEvents(t *testing.T) {
+	c := service.NewContainer()
+	server := httptest.NewServer(service.AdminHandler(c))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/events", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	c.Register(blockingService("db"))
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	assert.Equal(t, "id: 1", lines[0])
+	assert.Equal(t, "event: registered", lines[1])
+	assert.Contains(t, lines[2], `"service":"db"`)
+}
@@ -2,12 +2,18 @@ package service
 
 import (
 	"context"
+	"time"
 )
 
 type Builder struct {
-	name string
-	init InitFunc
-	run  RunFunc
+	name   string
+	init   InitFunc
+	run    RunFunc
+	stop   StopFunc
+	ready  ReadyFunc
+	health HealthFunc
+	close  CloseFunc
+	opts   []Option
 }
 
 func New(name string) *Builder {
@@ -33,10 +39,92 @@ func (b *Builder) Run(f RunFunc) *Builder {
 	return b
 }
 
+// Stop sets a function that is called to gracefully shut down the service, see Stopper
+func (b *Builder) Stop(f StopFunc) *Builder {
+	b.stop = f
+	return b
+}
+
+// Ready sets a function that blocks until the service is ready, see Readier
+func (b *Builder) Ready(f ReadyFunc) *Builder {
+	b.ready = f
+	return b
+}
+
+// Health sets a function that checks the health of the service, see HealthChecker
+func (b *Builder) Health(f HealthFunc) *Builder {
+	b.health = f
+	return b
+}
+
+// Close sets a function that releases resources after all services stopped, see Closer
+func (b *Builder) Close(f CloseFunc) *Builder {
+	b.close = f
+	return b
+}
+
+// StopTimeout sets the time the service gets to stop gracefully
+func (b *Builder) StopTimeout(timeout time.Duration) *Builder {
+	b.opts = append(b.opts, StopTimeout(timeout))
+	return b
+}
+
+// InitTimeout limits the time of each call to the init function
+func (b *Builder) InitTimeout(timeout time.Duration) *Builder {
+	b.opts = append(b.opts, InitTimeout(timeout))
+	return b
+}
+
+// InitRetry sets the InitRetryPolicy of the service
+func (b *Builder) InitRetry(policy InitRetryPolicy) *Builder {
+	b.opts = append(b.opts, InitRetry(policy))
+	return b
+}
+
+// Restart sets the RestartPolicy of the service
+func (b *Builder) Restart(policy RestartPolicy) *Builder {
+	b.opts = append(b.opts, Restart(policy))
+	return b
+}
+
+// Critical defines if a failure of the service affects other services, see the Critical option
+func (b *Builder) Critical(critical bool) *Builder {
+	b.opts = append(b.opts, Critical(critical))
+	return b
+}
+
+// DependsOn declares the names of services that must be started before and stopped after this service
+func (b *Builder) DependsOn(names ...string) *Builder {
+	b.opts = append(b.opts, DependsOn(names...))
+	return b
+}
+
 func (b *Builder) Register(container *Container) {
-	container.Register(&genericService{b.name, b.init, b.run})
+	container.Register(b.build(), b.opts...)
 }
 
 func (b *Builder) RegisterDefault() {
-	Default().Register(&genericService{b.name, b.init, b.run})
+	Default().Register(b.build(), b.opts...)
+}
+
+// Add adds the service with all options of the builder to the container, see Container.Add
+func (b *Builder) Add(ctx context.Context, container *Container) error {
+	return container.Add(ctx, b.build(), b.opts...)
+}
+
+// Build returns the service as Runner. Options of the builder, e.g. DependsOn, are only applied by Register and Add.
+func (b *Builder) Build() Runner {
+	return b.build()
+}
+
+func (b *Builder) build() *genericService {
+	return &genericService{
+		name:   b.name,
+		init:   b.init,
+		run:    b.run,
+		stop:   b.stop,
+		ready:  b.ready,
+		health: b.health,
+		close:  b.close,
+	}
 }
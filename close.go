@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SetCloseTimeout limits the time each service gets to close, see Closer.
+// A value of 0 waits forever, which is the default.
+func (c *Container) SetCloseTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closeTimeout = timeout
+}
+
+// closeAll closes all initialized services in reverse init order
+func (c *Container) closeAll() {
+	c.mu.Lock()
+	var rcs []*runContext
+	for i := len(c.initOrder) - 1; i >= 0; i-- {
+		if rc, ok := c.runContexts[c.initOrder[i]]; ok {
+			rcs = append(rcs, rc)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, rc := range rcs {
+		_ = c.closeOne(rc)
+	}
+}
+
+// shutdownErrors returns the errors returned by Stop and Close of all services joined
+func (c *Container) shutdownErrors() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for _, s := range c.order {
+		if rc, ok := c.runContexts[s.name]; ok {
+			errs = append(errs, rc.stopErr, rc.closeErr)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeOne calls the optional Close method of a service and records the error
+func (c *Container) closeOne(rc *runContext) error {
+	closer, ok := rc.service.service.(Closer)
+	if !ok {
+		return nil
+	}
+	c.mu.Lock()
+	timeout := c.closeTimeout
+	c.mu.Unlock()
+
+	ctx := context.Background()
+	if timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	c.logger().Info("Closing service", "name", rc.service.name)
+	// Close might ignore ctx, do not wait longer than the timeout
+	result := make(chan error, 1)
+	go func() {
+		result <- c.safeCall(rc.service, func() error {
+			return closer.Close(ctx)
+		})
+	}()
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = fmt.Errorf("close did not return within timeout: %w", ctx.Err())
+	}
+	if err == nil {
+		return nil
+	}
+	c.logger().Error("Failed to close service", "name", rc.service.name, "error", err)
+	c.onFailed(LifecycleEvent{Service: rc.service.name, Phase: PhaseClose, Err: err})
+	serviceErr := &ServiceError{Service: rc.service.name, Phase: PhaseClose, Err: err}
+	c.mu.Lock()
+	rc.closeErr = serviceErr
+	c.mu.Unlock()
+	return serviceErr
+}
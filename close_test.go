@@ -0,0 +1,142 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func closingService(log *eventLog, name string) *service.Builder {
+	return service.New(name).
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			log.add("stopped " + name)
+			return nil
+		}).
+		Close(func(ctx context.Context) error {
+			log.add("close " + name)
+			return nil
+		})
+}
+
+func TestClose(t *testing.T) {
+	log := &eventLog{}
+	c := service.NewContainer()
+	closingService(log, "api").DependsOn("db").Register(c)
+	closingService(log, "db").Register(c)
+	closingService(log, "cache").Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.StopAll()
+	err = c.Wait(context.Background())
+	require.NoError(t, err)
+
+	events := log.get()
+	require.Len(t, events, 6)
+	// All services are stopped before the first one is closed
+	assert.ElementsMatch(t, []string{"stopped api", "stopped db", "stopped cache"}, events[:3])
+	// Reverse init order: db, api, cache
+	assert.Equal(t, []string{"close cache", "close api", "close db"}, events[3:])
+}
+
+func TestCloseAfterInitFailure(t *testing.T) {
+	log := &eventLog{}
+	c := service.NewContainer()
+	closingService(log, "db").Register(c)
+	closingService(log, "api").
+		Init(func(ctx context.Context) error {
+			return errors.New("no connection")
+		}).
+		Register(c)
+
+	err := c.Run(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, []string{"close db"}, log.get())
+}
+
+func TestCloseErrorsAndTimeout(t *testing.T) {
+	c := service.NewContainer()
+	c.SetCloseTimeout(10 * time.Millisecond)
+	closeErr := errors.New("flush failed")
+	service.New("db").
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Close(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}).
+		Register(c)
+	service.New("cache").
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Close(func(ctx context.Context) error {
+			return closeErr
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.StopAll()
+	err = c.Wait(context.Background())
+	assert.ErrorIs(t, err, closeErr)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	errs := serviceErrors(err)
+	require.Len(t, errs, 2)
+	assert.Equal(t, service.PhaseClose, errs[0].Phase)
+	assert.ErrorIs(t, c.ServiceErrors()["cache"], closeErr)
+}
+
+func TestCloseWhenServicesReturn(t *testing.T) {
+	log := &eventLog{}
+	c := service.NewContainer()
+	service.New("migration").
+		Run(func(ctx context.Context) error {
+			log.add("stopped migration")
+			return nil
+		}).
+		Close(func(ctx context.Context) error {
+			log.add("close migration")
+			return nil
+		}).
+		Register(c)
+
+	err := c.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stopped migration", "close migration"}, log.get())
+}
+
+func TestCloseIgnoresContext(t *testing.T) {
+	c := service.NewContainer()
+	c.SetCloseTimeout(10 * time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	service.New("db").
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Close(func(ctx context.Context) error {
+			<-release
+			return nil
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.StopAll()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = c.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, c.ServiceErrors()["db"], context.DeadlineExceeded)
+}
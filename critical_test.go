@@ -0,0 +1,123 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestOptionalServiceFails(t *testing.T) {
+	c := service.NewContainer()
+	s1 := &testService{Name: "s1"}
+	c.Register(s1)
+	metrics := &testService{Name: "metrics", ErrorDuringRun: fmt.Errorf("push failed")}
+	c.Register(metrics, service.Critical(false))
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	<-s1.startedCh
+	assert.Eventually(t, func() bool {
+		return len(c.ServiceErrors()) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 1, c.RunningCount())
+	assertServiceStillRunning(t, s1)
+	assert.Equal(t, service.Degraded, c.Health().Status)
+
+	c.StopAll()
+	c.WaitAllStopped()
+	assertServiceStartedAndStopped(t, s1)
+}
+
+func TestOptionalServiceWithBuilder(t *testing.T) {
+	c := service.NewContainer()
+	s1 := &testService{Name: "s1"}
+	c.Register(s1)
+	service.New("cache-warmer").
+		Critical(false).
+		Run(func(ctx context.Context) error {
+			return fmt.Errorf("cache not reachable")
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	<-s1.startedCh
+	assert.Eventually(t, func() bool {
+		return len(c.ServiceErrors()) == 1
+	}, time.Second, time.Millisecond)
+	assertServiceStillRunning(t, s1)
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
+
+func TestOptionalServiceFailsInit(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		c := service.NewContainer()
+		c.SetInitConcurrency(concurrency)
+		c.Register(blockingService("db"))
+		service.New("cache-warmer").
+			Critical(false).
+			Init(func(ctx context.Context) error {
+				return fmt.Errorf("cache not reachable")
+			}).
+			Register(c)
+		service.New("cache-stats").Critical(false).DependsOn("cache-warmer").Register(c)
+
+		err := c.StartAll(context.Background())
+		require.NoError(t, err, "concurrency %d", concurrency)
+		assert.Equal(t, 1, c.RunningCount())
+		state, _ := c.ServiceState("cache-warmer")
+		assert.Equal(t, service.StateFailed, state)
+		state, _ = c.ServiceState("cache-stats")
+		assert.Equal(t, service.StateRegistered, state)
+
+		c.StopAll()
+		c.WaitAllStopped()
+	}
+}
+
+func TestCriticalServiceDependsOnFailedOptionalService(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		c := service.NewContainer()
+		c.SetInitConcurrency(concurrency)
+		service.New("cache").
+			Critical(false).
+			Init(func(ctx context.Context) error {
+				return fmt.Errorf("cache not reachable")
+			}).
+			Register(c)
+		service.New("api").DependsOn("cache").Register(c)
+
+		err := c.StartAll(context.Background())
+		assert.EqualError(t, err, "failed to init service api: dependency 'cache' failed", "concurrency %d", concurrency)
+		c.WaitAllStopped()
+	}
+}
+
+func TestOptionalServiceNotReady(t *testing.T) {
+	c := service.NewContainer()
+	c.SetReadyTimeout(time.Second)
+	c.Register(blockingService("db"))
+	service.New("cache-warmer").
+		Critical(false).
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Ready(func(ctx context.Context) error {
+			return fmt.Errorf("cache not reachable")
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.RunningCount())
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
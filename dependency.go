@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// sortServices orders services so that every service comes after all services it depends on.
+// Services without dependencies between each other keep their order of registration.
+// Unknown dependencies and dependency cycles are reported as error.
+func sortServices(services []*serviceInfo) ([]*serviceInfo, error) {
+	byName := make(map[string]*serviceInfo, len(services))
+	for _, s := range services {
+		byName[s.name] = s
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[string]int, len(services))
+	sorted := make([]*serviceInfo, 0, len(services))
+	var path []string
+
+	var visit func(s *serviceInfo) error
+	visit = func(s *serviceInfo) error {
+		switch marks[s.name] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := append(slices.Clone(path[slices.Index(path, s.name):]), s.name)
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+		marks[s.name] = visiting
+		path = append(path, s.name)
+		for _, name := range s.dependsOn {
+			dep, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("service '%s' depends on unknown service '%s'", s.name, name)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		marks[s.name] = visited
+		sorted = append(sorted, s)
+		return nil
+	}
+
+	for _, s := range services {
+		if err := visit(s); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// dependents returns for each service name all services that directly depend on it
+func dependents(services []*serviceInfo) map[string][]string {
+	deps := map[string][]string{}
+	for _, s := range services {
+		for _, name := range s.dependsOn {
+			deps[name] = append(deps[name], s.name)
+		}
+	}
+	return deps
+}
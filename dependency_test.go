@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"context"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventLog records lifecycle events of multiple services in order
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.events...)
+}
+
+func loggingService(log *eventLog, name string) *service.Builder {
+	return service.New(name).
+		Init(func(ctx context.Context) error {
+			log.add("init " + name)
+			return nil
+		}).
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			// Give dependencies the chance to stop too early
+			time.Sleep(10 * time.Millisecond)
+			log.add("stop " + name)
+			return nil
+		})
+}
+
+func TestDependencyOrder(t *testing.T) {
+	c := service.NewContainer()
+	log := &eventLog{}
+
+	loggingService(log, "api").DependsOn("db", "cache").Register(c)
+	loggingService(log, "cache").DependsOn("db").Register(c)
+	loggingService(log, "db").Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.StopAll()
+	c.WaitAllStopped()
+
+	assert.Equal(t, []string{
+		"init db", "init cache", "init api",
+		"stop api", "stop cache", "stop db",
+	}, log.get())
+}
+
+func TestDependencyOrderOnContextCancel(t *testing.T) {
+	c := service.NewContainer()
+	log := &eventLog{}
+
+	loggingService(log, "api").DependsOn("db").Register(c)
+	loggingService(log, "db").Register(c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := c.StartAll(ctx)
+	require.NoError(t, err)
+	cancel()
+	c.WaitAllStopped()
+
+	assert.Equal(t, []string{"init db", "init api", "stop api", "stop db"}, log.get())
+}
+
+func TestDependencyCycle(t *testing.T) {
+	c := service.NewContainer()
+	log := &eventLog{}
+
+	loggingService(log, "a").DependsOn("b").Register(c)
+	loggingService(log, "b").DependsOn("c").Register(c)
+	loggingService(log, "c").DependsOn("a").Register(c)
+
+	err := c.StartAll(context.Background())
+	require.EqualError(t, err, "dependency cycle detected: a -> b -> c -> a")
+	c.WaitAllStopped()
+	assert.Empty(t, log.get())
+}
+
+func TestDependencyMissing(t *testing.T) {
+	c := service.NewContainer()
+	log := &eventLog{}
+
+	loggingService(log, "api").DependsOn("db").Register(c)
+
+	err := c.StartAll(context.Background())
+	require.EqualError(t, err, "service 'api' depends on unknown service 'db'")
+	assert.Empty(t, log.get())
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Add registers a service at any time. When the container is already started,
+// the service is initialized and started right away with ctx passed to Init.
+// In contrast to Register, errors are returned instead of causing a panic.
+// All dependencies of the service must already be registered.
+func (c *Container) Add(ctx context.Context, service Runner, opts ...Option) error {
+	defer c.beginChange()()
+	s := newServiceInfo(service, opts...)
+
+	c.mu.Lock()
+	if c.lookup(s.name) != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("service '%s' already registered", s.name)
+	}
+	for _, dep := range s.dependsOn {
+		if c.lookup(dep) == nil {
+			c.mu.Unlock()
+			return fmt.Errorf("service '%s' depends on unknown service '%s'", s.name, dep)
+		}
+	}
+	started := c.order != nil
+	if started && c.runCtx.Err() != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("service '%s' not added, container is stopping", s.name)
+	}
+	c.services = append(c.services, s)
+	if started {
+		c.order = append(c.order, s)
+	}
+	c.mu.Unlock()
+	c.logger().Info("Registered service", "name", s.name)
+	c.onRegistered(s)
+
+	if !started {
+		return nil
+	}
+	if err := c.initOne(ctx, s); err != nil {
+		c.drop(s.name)
+		return err
+	}
+	return c.runOne(s)
+}
+
+// Remove stops a single service gracefully, closes it and removes it from the container.
+// Services that other services depend on can not be removed.
+// Remove returns an error when the service does not stop before ctx is done, the service is removed anyway.
+func (c *Container) Remove(ctx context.Context, name string) error {
+	c.mu.Lock()
+	if c.lookup(name) == nil {
+		c.mu.Unlock()
+		return fmt.Errorf("service '%s' not registered", name)
+	}
+	for _, s := range c.services {
+		if slices.Contains(s.dependsOn, name) {
+			c.mu.Unlock()
+			return fmt.Errorf("service '%s' is required by service '%s'", name, s.name)
+		}
+	}
+	rc := c.runContexts[name]
+	c.mu.Unlock()
+
+	var err error
+	if rc != nil {
+		c.logger().Info("Removing service", "name", name)
+		c.stopOne(ctx, rc)
+		if !rc.isDone() {
+			err = fmt.Errorf("service '%s' did not stop in time", name)
+		}
+		c.mu.Lock()
+		initialized := slices.Contains(c.initOrder, name)
+		c.mu.Unlock()
+		if initialized {
+			err = errors.Join(err, c.closeOne(rc))
+		}
+	}
+	c.drop(name)
+	return err
+}
+
+// beginChange marks a change of the running services, Wait does not return before the returned func is called
+func (c *Container) beginChange() func() {
+	c.mu.Lock()
+	c.changes++
+	c.mu.Unlock()
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.changes--
+		if c.changed != nil {
+			close(c.changed)
+			c.changed = nil
+		}
+	}
+}
+
+// drop removes a service from all lists of the container
+func (c *Container) drop(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	match := func(s *serviceInfo) bool {
+		return s.name == name
+	}
+	// Clone before deleting, since StartAll might still iterate the old slices
+	c.services = slices.DeleteFunc(slices.Clone(c.services), match)
+	if c.order != nil {
+		c.order = slices.DeleteFunc(slices.Clone(c.order), match)
+	}
+	c.initOrder = slices.DeleteFunc(slices.Clone(c.initOrder), func(n string) bool {
+		return n == name
+	})
+	delete(c.runContexts, name)
+}
+
+// StopService stops a single running service gracefully, other services keep running.
+// Services that running services depend on can not be stopped.
+// The service stays registered and can be started again with StartService.
+func (c *Container) StopService(ctx context.Context, name string) error {
+	c.mu.Lock()
+	rc, ok := c.runContexts[name]
+	if !ok {
+		c.mu.Unlock()
+		return fmt.Errorf("service '%s' not started", name)
+	}
+	for _, s := range c.services {
+		if d, ok := c.runContexts[s.name]; ok && slices.Contains(s.dependsOn, name) && !d.isDone() {
+			c.mu.Unlock()
+			return fmt.Errorf("service '%s' is required by running service '%s'", name, s.name)
+		}
+	}
+	c.mu.Unlock()
+
+	c.logger().Info("Stopping single service", "name", name)
+	c.stopOne(ctx, rc)
+	if !rc.isDone() {
+		return fmt.Errorf("service '%s' did not stop in time", name)
+	}
+	return nil
+}
+
+// StartService starts a single stopped service of a running container with its own context.
+// Services that were never initialized are initialized first with ctx passed to Init.
+// All dependencies of the service must be running.
+func (c *Container) StartService(ctx context.Context, name string) error {
+	defer c.beginChange()()
+	c.mu.Lock()
+	s := c.lookup(name)
+	if s == nil {
+		c.mu.Unlock()
+		return fmt.Errorf("service '%s' not registered", name)
+	}
+	if c.order == nil {
+		c.mu.Unlock()
+		return fmt.Errorf("service '%s' not started, container is not started", name)
+	}
+	if err := c.runCtx.Err(); err != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("service '%s' not started, container is stopping: %w", name, err)
+	}
+	for _, dep := range s.dependsOn {
+		if d, ok := c.runContexts[dep]; !ok || d.isDone() {
+			c.mu.Unlock()
+			return fmt.Errorf("service '%s' not started, dependency '%s' is not running", name, dep)
+		}
+	}
+	old, ok := c.runContexts[name]
+	// Services that failed to initialize are initialized again
+	initialized := ok && old.initErr == nil
+	if ok && !old.isDone() {
+		c.mu.Unlock()
+		return fmt.Errorf("service '%s' already running", name)
+	}
+	if !initialized {
+		delete(c.runContexts, name)
+	}
+	if initialized {
+		// Keep the history of the service, but run it with a fresh context
+		rc := newRunContext(c.runCtx, s, StateInitialized)
+		rc.since = maps.Clone(old.since)
+		rc.initDuration = old.initDuration
+		rc.err = old.err
+		rc.restarts = old.restarts
+		rc.failures = old.failures
+		rc.stopDuration = old.stopDuration
+		c.runContexts[name] = rc
+	}
+	c.mu.Unlock()
+
+	if !initialized {
+		if err := c.initOne(ctx, s); err != nil {
+			c.mu.Lock()
+			delete(c.runContexts, name)
+			c.mu.Unlock()
+			return err
+		}
+	}
+	return c.runOne(s)
+}
+
+// RestartService stops a single service gracefully and starts it again
+func (c *Container) RestartService(ctx context.Context, name string) error {
+	// Wait must not return while the service is stopped for the restart
+	defer c.beginChange()()
+	if err := c.StopService(ctx, name); err != nil {
+		return err
+	}
+	return c.StartService(ctx, name)
+}
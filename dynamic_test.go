@@ -0,0 +1,281 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAddAndRemove(t *testing.T) {
+	c := service.NewContainer()
+	s1 := &testService{Name: "s1"}
+	c.Register(s1)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	<-s1.startedCh
+
+	s2 := &testService{Name: "s2"}
+	err = c.Add(context.Background(), s2, service.DependsOn(s1.String()))
+	require.NoError(t, err)
+	<-s2.startedCh
+	assert.Equal(t, 2, c.RunningCount())
+
+	err = c.Add(context.Background(), &testService{Name: "s2"})
+	assert.EqualError(t, err, "service 'testService.s2' already registered")
+
+	err = c.Remove(context.Background(), s1.String())
+	assert.EqualError(t, err, "service 'testService.s1' is required by service 'testService.s2'")
+
+	err = c.Remove(context.Background(), s2.String())
+	require.NoError(t, err)
+	assertServiceStartedAndStopped(t, s2)
+	assert.Equal(t, []string{s1.String()}, c.ServiceNames())
+	assertServiceStillRunning(t, s1)
+
+	c.StopAll()
+	c.WaitAllStopped()
+	assertServiceStartedAndStopped(t, s1)
+	assert.Len(t, c.ServiceErrors(), 0)
+}
+
+func TestAddFailingInit(t *testing.T) {
+	c := service.NewContainer()
+	s1 := &testService{Name: "s1"}
+	c.Register(s1)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	<-s1.startedCh
+
+	initErr := errors.New("init failed")
+	err = c.Add(context.Background(), &testService{Name: "s2", ErrorDuringInit: initErr})
+	require.ErrorIs(t, err, initErr)
+	assert.Equal(t, []string{s1.String()}, c.ServiceNames())
+	assertServiceStillRunning(t, s1)
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
+
+type slowInitService struct {
+	started chan struct{}
+	fail    chan struct{}
+}
+
+func (s *slowInitService) Init(ctx context.Context) error {
+	close(s.started)
+	<-s.fail
+	return errors.New("init failed")
+}
+
+func (s *slowInitService) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func TestAddFailingInitDuringStopAll(t *testing.T) {
+	c := service.NewContainer()
+	c.Register(blockingService("s1"))
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+
+	initStarted := make(chan struct{})
+	failInit := make(chan struct{})
+	added := make(chan error)
+	go func() {
+		added <- c.Add(context.Background(), &slowInitService{started: initStarted, fail: failInit})
+	}()
+	<-initStarted
+	c.StopAll()
+	// Fail the init after the shutdown already waits for s2
+	require.Eventually(t, func() bool {
+		state, _ := c.ServiceState("s1")
+		return state == service.StateStopped
+	}, time.Second, time.Millisecond)
+	close(failInit)
+	assert.Error(t, <-added)
+
+	stopped := make(chan struct{})
+	go func() {
+		c.WaitAllStopped()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("WaitAllStopped blocked after failed init")
+	}
+}
+
+func TestAddAndRemoveConcurrently(t *testing.T) {
+	c := service.NewContainer()
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := &testService{Name: fmt.Sprintf("tenant-%d", i)}
+			assert.NoError(t, c.Add(context.Background(), s))
+			if i%2 == 0 {
+				assert.NoError(t, c.Remove(context.Background(), s.String()))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, c.ServiceNames(), 10)
+
+	c.StopAll()
+	c.WaitAllStopped()
+	assert.Equal(t, 0, c.RunningCount())
+}
+
+func TestStopStartAndRestartService(t *testing.T) {
+	c := service.NewContainer()
+	s1 := &testService{Name: "s1"}
+	c.Register(s1)
+
+	var runs atomic.Int32
+	started := make(chan struct{}, 10)
+	service.New("consumer").Run(func(ctx context.Context) error {
+		runs.Add(1)
+		started <- struct{}{}
+		<-ctx.Done()
+		// Errors of deliberately stopped services must not stop other services
+		return errors.New("consumer interrupted")
+	}).Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	<-s1.startedCh
+	<-started
+
+	err = c.StopService(context.Background(), "consumer")
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.RunningCount())
+	assertServiceStillRunning(t, s1)
+
+	err = c.StartService(context.Background(), "consumer")
+	require.NoError(t, err)
+	<-started
+	err = c.StartService(context.Background(), "consumer")
+	assert.EqualError(t, err, "service 'consumer' already running")
+
+	err = c.RestartService(context.Background(), "consumer")
+	require.NoError(t, err)
+	<-started
+	assert.Equal(t, int32(3), runs.Load())
+	assert.Equal(t, 2, c.RunningCount())
+	assertServiceStillRunning(t, s1)
+
+	c.StopAll()
+	c.WaitAllStopped()
+	assertServiceStartedAndStopped(t, s1)
+}
+
+func TestStopServiceWithRunningDependents(t *testing.T) {
+	c := service.NewContainer()
+	log := &eventLog{}
+	loggingService(log, "db").Register(c)
+	loggingService(log, "api").DependsOn("db").Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+
+	err = c.StopService(context.Background(), "db")
+	assert.EqualError(t, err, "service 'db' is required by running service 'api'")
+
+	require.NoError(t, c.StopService(context.Background(), "api"))
+	require.NoError(t, c.StopService(context.Background(), "db"))
+	assert.Equal(t, 0, c.RunningCount())
+
+	err = c.StartService(context.Background(), "api")
+	assert.EqualError(t, err, "service 'api' not started, dependency 'db' is not running")
+	state, _ := c.ServiceState("api")
+	assert.Equal(t, service.StateStopped, state)
+
+	require.NoError(t, c.StartService(context.Background(), "db"))
+	require.NoError(t, c.StartService(context.Background(), "api"))
+	assert.Eventually(t, func() bool {
+		return c.RunningCount() == 2
+	}, time.Second, time.Millisecond)
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
+
+func TestStopServiceWithErrorFromStop(t *testing.T) {
+	c := service.NewContainer()
+	c.Register(blockingService("other"))
+	shutdown := make(chan struct{})
+	returned := make(chan struct{})
+	service.New("api").
+		Run(func(ctx context.Context) error {
+			// Like http.Server.ListenAndServe returning after Shutdown
+			<-shutdown
+			close(returned)
+			return errors.New("server closed")
+		}).
+		Stop(func(ctx context.Context) error {
+			close(shutdown)
+			// The context of Run is only canceled after Stop returned
+			<-returned
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, c.StopService(context.Background(), "api"))
+
+	// Give a wrongly triggered cascade the time to stop other services
+	time.Sleep(10 * time.Millisecond)
+	state, _ := c.ServiceState("other")
+	assert.Equal(t, service.StateRunning, state)
+	assert.Equal(t, 1, c.RunningCount())
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
+
+func TestWaitForRestartedAndAddedServices(t *testing.T) {
+	c := service.NewContainer()
+	c.Register(blockingService("a"))
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	waited := make(chan struct{})
+	go func() {
+		c.WaitAllStopped()
+		close(waited)
+	}()
+
+	require.NoError(t, c.RestartService(context.Background(), "a"))
+	require.NoError(t, c.Add(context.Background(), blockingService("b")))
+	require.NoError(t, c.StopService(context.Background(), "a"))
+	select {
+	case <-waited:
+		t.Fatal("WaitAllStopped returned while a service is running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	assert.Equal(t, 1, c.RunningCount())
+
+	c.StopAll()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("WaitAllStopped did not return")
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import "fmt"
+
+// ServiceError is returned when a single service failed, e.g. as part of the joined errors returned by Container.Run.
+// Use errors.As to access the service name and the phase the service failed in.
+type ServiceError struct {
+	Service string
+	Phase   Phase
+	Err     error
+}
+
+func (e *ServiceError) Error() string {
+	return fmt.Sprintf("failed to %s service %s: %v", e.Phase, e.Service, e.Err)
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func serviceErrors(err error) []*service.ServiceError {
+	var result []*service.ServiceError
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			result = append(result, serviceErrors(e)...)
+		}
+		return result
+	}
+	var serviceErr *service.ServiceError
+	if errors.As(err, &serviceErr) {
+		result = append(result, serviceErr)
+	}
+	return result
+}
+
+func TestRunErrors(t *testing.T) {
+	runErr := errors.New("connection lost")
+	stopErr := errors.New("flush failed")
+	c := service.NewContainer()
+	service.New("db").
+		Run(func(ctx context.Context) error {
+			return runErr
+		}).
+		Register(c)
+	service.New("cache").
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Stop(func(ctx context.Context) error {
+			return stopErr
+		}).
+		Register(c)
+
+	err := c.Run(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, runErr)
+	assert.ErrorIs(t, err, stopErr)
+
+	errs := serviceErrors(err)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "db", errs[0].Service)
+	assert.Equal(t, service.PhaseRun, errs[0].Phase)
+	assert.Equal(t, "cache", errs[1].Service)
+	assert.Equal(t, service.PhaseStop, errs[1].Phase)
+	assert.EqualError(t, errs[1], "failed to stop service cache: flush failed")
+}
+
+func TestRunInitErrors(t *testing.T) {
+	initErr := errors.New("no connection")
+	c := service.NewContainer()
+	service.New("db").
+		Init(func(ctx context.Context) error {
+			return initErr
+		}).
+		Register(c)
+
+	err := c.Run(context.Background())
+	assert.ErrorIs(t, err, initErr)
+	var serviceErr *service.ServiceError
+	require.True(t, errors.As(err, &serviceErr))
+	assert.Equal(t, "db", serviceErr.Service)
+	assert.Equal(t, service.PhaseInit, serviceErr.Phase)
+}
+
+func TestWait(t *testing.T) {
+	c := service.NewContainer()
+	c.Register(blockingService("s1"))
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = c.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, c.RunningCount())
+
+	c.StopAll()
+	err = c.Wait(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, c.RunningCount())
+}
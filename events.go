@@ -0,0 +1,140 @@
+package service
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// EventType describes what happened to a service
+type EventType string
+
+const (
+	EventRegistered       EventType = "registered"
+	EventInitStarted      EventType = "init-started"
+	EventInitFinished     EventType = "init-finished"
+	EventRunStarted       EventType = "run-started"
+	EventStopped          EventType = "stopped"
+	EventFailed           EventType = "failed"
+	EventRestartScheduled EventType = "restart-scheduled"
+	// EventShutdown is sent once when the container is stopped, Service is empty
+	EventShutdown EventType = "shutdown"
+)
+
+// Event is a lifecycle event delivered to subscribers, see Container.Subscribe
+type Event struct {
+	// Seq is increased by one for every event of the container, gaps indicate dropped events
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+	Type EventType `json:"type"`
+	// Service name, empty for events of the container
+	Service string `json:"service,omitempty"`
+	Phase   Phase  `json:"phase,omitempty"`
+	// Duration of the phase for finished and stopped events, the restart delay for restart events
+	Duration time.Duration `json:"duration,omitempty"`
+	Err      error         `json:"error,omitempty"`
+}
+
+// MarshalJSON renders the duration and error as strings
+func (e Event) MarshalJSON() ([]byte, error) {
+	type event Event
+	var duration, err string
+	if e.Duration != 0 {
+		duration = e.Duration.String()
+	}
+	if e.Err != nil {
+		err = e.Err.Error()
+	}
+	return json.Marshal(struct {
+		event
+		Duration string `json:"duration,omitempty"`
+		Err      string `json:"error,omitempty"`
+	}{
+		event:    event(e),
+		Duration: duration,
+		Err:      err,
+	})
+}
+
+// EventPolicy defines how events are delivered to subscribers that are not able to keep up
+type EventPolicy int
+
+const (
+	// DropEvents drops events when the buffer of a subscriber is full, which is the default
+	DropEvents EventPolicy = iota
+	// BlockEvents waits until the subscriber received the event. This blocks the services of the container!
+	BlockEvents
+)
+
+// broker delivers events to all subscribers
+type broker struct {
+	mu          sync.Mutex
+	seq         uint64
+	policy      EventPolicy
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	ch     chan Event
+	done   chan struct{}
+	cancel sync.Once
+}
+
+// SetEventPolicy defines how events are delivered to subscribers with a full buffer
+func (c *Container) SetEventPolicy(policy EventPolicy) {
+	c.events.mu.Lock()
+	defer c.events.mu.Unlock()
+	c.events.policy = policy
+}
+
+// Subscribe returns a channel that receives all lifecycle events of the container in order.
+// buffer is the number of events that are buffered before the event policy applies, see SetEventPolicy.
+// Call cancel to unsubscribe, the channel gets closed afterwards.
+func (c *Container) Subscribe(buffer int) (<-chan Event, func()) {
+	sub := &subscriber{
+		ch:   make(chan Event, buffer),
+		done: make(chan struct{}),
+	}
+	c.events.mu.Lock()
+	if c.events.subscribers == nil {
+		c.events.subscribers = map[*subscriber]struct{}{}
+	}
+	c.events.subscribers[sub] = struct{}{}
+	c.events.mu.Unlock()
+
+	cancel := func() {
+		sub.cancel.Do(func() {
+			// Unblock a pending publish before taking the lock
+			close(sub.done)
+			c.events.mu.Lock()
+			delete(c.events.subscribers, sub)
+			close(sub.ch)
+			c.events.mu.Unlock()
+		})
+	}
+	return sub.ch, cancel
+}
+
+// publish sends an event to all subscribers, must not be called with c.mu held
+func (c *Container) publish(e Event) {
+	b := &c.events
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	e.Seq = b.seq
+	e.Time = time.Now()
+	for sub := range b.subscribers {
+		if b.policy == BlockEvents {
+			select {
+			case sub.ch <- e:
+			case <-sub.done:
+			}
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			c.logger().Debug("Dropped event for slow subscriber", "seq", e.Seq, "type", e.Type, "name", e.Service)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func collectEvents(events <-chan service.Event) []service.Event {
+	var result []service.Event
+	for e := range events {
+		result = append(result, e)
+	}
+	return result
+}
+
+func TestSubscribe(t *testing.T) {
+	c := service.NewContainer()
+	events, cancel := c.Subscribe(100)
+
+	service.New("db").
+		Restart(service.RestartPolicy{Mode: service.RestartOnFailure, MaxRestarts: 1, Backoff: fastBackoff}).
+		Init(func(ctx context.Context) error {
+			return nil
+		}).
+		Run(func(ctx context.Context) error {
+			return errors.New("connection lost")
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.WaitAllStopped()
+	c.StopAll()
+	cancel()
+
+	var types []service.EventType
+	var last uint64
+	for _, e := range collectEvents(events) {
+		types = append(types, e.Type)
+		assert.Equal(t, last+1, e.Seq)
+		assert.False(t, e.Time.IsZero())
+		last = e.Seq
+	}
+	assert.Equal(t, []service.EventType{
+		service.EventRegistered,
+		service.EventInitStarted,
+		service.EventInitFinished,
+		service.EventRunStarted,
+		service.EventStopped,
+		service.EventFailed,
+		service.EventRestartScheduled,
+		service.EventRunStarted,
+		service.EventStopped,
+		service.EventFailed,
+		service.EventShutdown,
+	}, types)
+}
+
+func TestSubscribeDrop(t *testing.T) {
+	c := service.NewContainer()
+	events, cancel := c.Subscribe(1)
+	c.Register(blockingService("s1"))
+	c.Register(blockingService("s2"))
+	cancel()
+
+	received := collectEvents(events)
+	require.Len(t, received, 1)
+	assert.Equal(t, "s1", received[0].Service)
+}
+
+func TestSubscribeBlock(t *testing.T) {
+	c := service.NewContainer()
+	c.SetEventPolicy(service.BlockEvents)
+	events, cancel := c.Subscribe(0)
+
+	registered := make(chan struct{})
+	go func() {
+		c.Register(blockingService("s1"))
+		c.Register(blockingService("s2"))
+		close(registered)
+	}()
+
+	e := <-events
+	assert.Equal(t, "s1", e.Service)
+	e = <-events
+	assert.Equal(t, "s2", e.Service)
+	assert.Equal(t, uint64(2), e.Seq)
+	<-registered
+
+	// A canceled subscriber must not block the container
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		c.Register(blockingService("s3"))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Register blocked after cancel")
+	}
+}
+
+func TestEventJSON(t *testing.T) {
+	data, err := json.Marshal(service.Event{
+		Seq:      1,
+		Type:     service.EventStopped,
+		Service:  "db",
+		Phase:    service.PhaseRun,
+		Duration: time.Second,
+		Err:      errors.New("connection lost"),
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"duration":"1s"`)
+	assert.Contains(t, string(data), `"error":"connection lost"`)
+	assert.Contains(t, string(data), `"type":"stopped"`)
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDegraded can be wrapped by errors returned from HealthChecker.Health
+// to report a service that is still working, but with limited functionality
+var ErrDegraded = errors.New("degraded")
+
+// HealthStatus is the result of a health check
+type HealthStatus string
+
+const (
+	Healthy   HealthStatus = "healthy"
+	Degraded  HealthStatus = "degraded"
+	Unhealthy HealthStatus = "unhealthy"
+)
+
+// worse returns the worse of both health states
+func (h HealthStatus) worse(other HealthStatus) HealthStatus {
+	rank := map[HealthStatus]int{Healthy: 0, Degraded: 1, Unhealthy: 2}
+	if rank[other] > rank[h] {
+		return other
+	}
+	return h
+}
+
+// ServiceHealth is the last known health of a single service
+type ServiceHealth struct {
+	Status HealthStatus `json:"status"`
+	Error  string       `json:"error,omitempty"`
+	// CheckedAt is the time of the last health check, zero if the service was not checked yet
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// HealthReport contains the health of all services and the overall health of the container
+type HealthReport struct {
+	Status   HealthStatus             `json:"status"`
+	Services map[string]ServiceHealth `json:"services"`
+}
+
+// SetHealthInterval enables periodic health checks of all running services that implement the HealthChecker interface.
+// Each check must finish within the interval. A value of 0 disables health checks, which is the default.
+func (c *Container) SetHealthInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.healthInterval = interval
+}
+
+// Health returns the health of all running and failed services based on the last health checks.
+// Running services are healthy until their first check, failed and restarting services are always unhealthy.
+// The overall status is the worst status of any service, non-critical services can only degrade the overall status.
+func (c *Container) Health() HealthReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := HealthReport{
+		Status:   Healthy,
+		Services: map[string]ServiceHealth{},
+	}
+	for _, s := range c.order {
+		rc, ok := c.runContexts[s.name]
+		if !ok {
+			continue
+		}
+		var health ServiceHealth
+		switch rc.state {
+		case StateRunning:
+			health = rc.health
+			if health.Status == "" {
+				health.Status = Healthy
+			}
+		case StateFailed, StateRestarting:
+			health = ServiceHealth{Status: Unhealthy}
+			if rc.err != nil {
+				health.Error = rc.err.Error()
+			}
+		default:
+			continue
+		}
+		report.Services[s.name] = health
+		status := health.Status
+		if s.optional && status == Unhealthy {
+			// Non-critical services can only degrade the container
+			status = Degraded
+		}
+		report.Status = report.Status.worse(status)
+	}
+	return report
+}
+
+// pollHealth checks the health of all services until ctx is done
+func (c *Container) pollHealth(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.checkHealth(ctx, interval)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkHealth runs the health checks of all running services in parallel
+func (c *Container) checkHealth(ctx context.Context, timeout time.Duration) {
+	c.mu.Lock()
+	var rcs []*runContext
+	for _, rc := range c.runContexts {
+		if _, ok := rc.service.service.(HealthChecker); ok && rc.state == StateRunning {
+			rcs = append(rcs, rc)
+		}
+	}
+	c.mu.Unlock()
+
+	wg := sync.WaitGroup{}
+	for _, rc := range rcs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			health := c.checkOne(ctx, rc, timeout)
+			c.mu.Lock()
+			rc.health = health
+			c.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+}
+
+func (c *Container) checkOne(ctx context.Context, rc *runContext, timeout time.Duration) ServiceHealth {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	health := ServiceHealth{Status: Healthy, CheckedAt: time.Now()}
+	// A panic is reported as unhealthy
+	err := c.safeCall(rc.service, func() error {
+		return rc.service.service.(HealthChecker).Health(ctx)
+	})
+	if err == nil {
+		return health
+	}
+
+	health.Status = Unhealthy
+	if errors.Is(err, ErrDegraded) {
+		health.Status = Degraded
+	}
+	health.Error = err.Error()
+	c.logger().Warn("Service health check failed", "name", rc.service.name, "status", health.Status, "error", err)
+	return health
+}
@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func healthService(name string, health *atomic.Value) *service.Builder {
+	return service.New(name).
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Health(func(ctx context.Context) error {
+			if err, ok := health.Load().(error); ok {
+				return err
+			}
+			return nil
+		})
+}
+
+func TestHealth(t *testing.T) {
+	c := service.NewContainer()
+	c.SetHealthInterval(5 * time.Millisecond)
+
+	var dbHealth, cacheHealth atomic.Value
+	healthService("db", &dbHealth).Register(c)
+	healthService("cache", &cacheHealth).Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	defer c.WaitAllStopped()
+	defer c.StopAll()
+
+	assert.Eventually(t, func() bool {
+		report := c.Health()
+		return report.Status == service.Healthy && !report.Services["db"].CheckedAt.IsZero()
+	}, time.Second, time.Millisecond)
+
+	cacheHealth.Store(fmt.Errorf("cache is cold: %w", service.ErrDegraded))
+	assert.Eventually(t, func() bool {
+		return c.Health().Status == service.Degraded
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, service.Healthy, c.Health().Services["db"].Status)
+
+	dbHealth.Store(errors.New("connection lost"))
+	assert.Eventually(t, func() bool {
+		return c.Health().Status == service.Unhealthy
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "connection lost", c.Health().Services["db"].Error)
+}
+
+func TestHealthOfFailedService(t *testing.T) {
+	c := service.NewContainer()
+	s1 := &testService{
+		Name:           "s1",
+		ErrorDuringRun: fmt.Errorf("service failed during run"),
+	}
+	c.Register(s1)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.WaitAllStopped()
+
+	report := c.Health()
+	assert.Equal(t, service.Unhealthy, report.Status)
+	assert.Equal(t, service.Unhealthy, report.Services[s1.String()].Status)
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// initAll initializes all services in order of their dependencies.
+// With an init concurrency > 1, independent services are initialized in parallel.
+// Failures of non-critical services are only logged, services depending on them are skipped.
+func (c *Container) initAll(ctx context.Context, order []*serviceInfo) error {
+	c.mu.Lock()
+	concurrency := c.initConcurrency
+	c.mu.Unlock()
+	if concurrency > 1 {
+		return c.initParallel(ctx, order, concurrency)
+	}
+
+	failed := map[string]bool{}
+	for _, s := range order {
+		if dep := failedDependency(s, failed); dep != "" {
+			failed[s.name] = true
+			if err := c.skipInit(s, dep); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.initOne(ctx, s); err != nil {
+			if !s.optional {
+				return err
+			}
+			c.logger().Warn("Optional service failed to initialize, other services keep running", "name", s.name, "error", err)
+			failed[s.name] = true
+		}
+	}
+	return nil
+}
+
+// failedDependency returns the name of the first dependency of the service that failed to initialize
+func failedDependency(s *serviceInfo, failed map[string]bool) string {
+	for _, dep := range s.dependsOn {
+		if failed[dep] {
+			return dep
+		}
+	}
+	return ""
+}
+
+// skipInit skips the init of a service whose dependency failed, only critical services return an error
+func (c *Container) skipInit(s *serviceInfo, dep string) error {
+	if !s.optional {
+		return &ServiceError{Service: s.name, Phase: PhaseInit, Err: fmt.Errorf("dependency '%s' failed", dep)}
+	}
+	c.logger().Warn("Optional service not initialized, dependency failed", "name", s.name, "dependency", dep)
+	return nil
+}
+
+// initialized reports if the service was initialized successfully and can be started
+func (c *Container) initialized(s *serviceInfo) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rc, ok := c.runContexts[s.name]
+	return ok && rc.initErr == nil
+}
+
+// initParallel initializes up to limit services at once.
+// Each service waits for the initialization of all its dependencies and is skipped when one of them failed.
+// All errors of failed inits of critical services are returned joined.
+func (c *Container) initParallel(ctx context.Context, order []*serviceInfo, limit int) error {
+	type initResult struct {
+		done chan struct{}
+		ok   bool
+		// reported is set when the failure of the service or its dependencies is already part of the errors
+		reported bool
+	}
+	results := make(map[string]*initResult, len(order))
+	for _, s := range order {
+		results[s.name] = &initResult{done: make(chan struct{})}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+		sem  = make(chan struct{}, limit)
+	)
+
+	for _, s := range order {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := results[s.name]
+			defer close(result.done)
+			for _, dep := range s.dependsOn {
+				<-results[dep].done
+				if results[dep].ok {
+					continue
+				}
+				result.reported = results[dep].reported
+				if !result.reported {
+					if err := c.skipInit(s, dep); err != nil {
+						mu.Lock()
+						errs = append(errs, err)
+						mu.Unlock()
+						result.reported = true
+					}
+				}
+				return
+			}
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := c.initOne(ctx, s); err != nil {
+				if s.optional {
+					c.logger().Warn("Optional service failed to initialize, other services keep running", "name", s.name, "error", err)
+					return
+				}
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				result.reported = true
+				return
+			}
+			result.ok = true
+		}()
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// InitRetryPolicy defines how often a failing Init is retried before the start of the container fails
+type InitRetryPolicy struct {
+	Backoff Backoff
+	// MaxAttempts limits the number of attempts including the first one, 0 allows unlimited attempts
+	MaxAttempts int
+	// MaxElapsed limits the time of all attempts including the delays between them, 0 means no limit.
+	// Init is not retried when neither MaxAttempts nor MaxElapsed is set.
+	MaxElapsed time.Duration
+}
+
+// retry returns the delay before the next attempt or false when Init must not be retried
+func (p InitRetryPolicy) retry(attempts int, elapsed time.Duration) (time.Duration, bool) {
+	if p.MaxAttempts == 0 && p.MaxElapsed == 0 {
+		return 0, false
+	}
+	if p.MaxAttempts > 0 && attempts >= p.MaxAttempts {
+		return 0, false
+	}
+	delay := p.Backoff.delay(attempts - 1)
+	if p.MaxElapsed > 0 && elapsed+delay >= p.MaxElapsed {
+		return 0, false
+	}
+	return delay, true
+}
+
+// callInit calls Init of the service with the init timeout and retries it according to the InitRetryPolicy.
+// When Init failed multiple times, the errors of all attempts are returned.
+func (c *Container) callInit(ctx context.Context, s *serviceInfo, initer Initer) error {
+	start := time.Now()
+	var errs []error
+	for attempt := 1; ; attempt++ {
+		c.logger().Info("Initializing service", "name", s.name, "attempt", attempt)
+		err := c.safeCall(s, func() error {
+			initCtx := ctx
+			if s.initTimeout > 0 {
+				var cancel context.CancelFunc
+				initCtx, cancel = context.WithTimeout(ctx, s.initTimeout)
+				defer cancel()
+			}
+			return initer.Init(initCtx)
+		})
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+
+		delay, retry := s.initRetry.retry(attempt, time.Since(start))
+		if !retry || ctx.Err() != nil {
+			break
+		}
+		c.logger().Warn("Failed to initialize service, retrying", "name", s.name, "attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return attemptsError(errs)
+		}
+	}
+	return attemptsError(errs)
+}
+
+// attemptsError returns a single error or all errors numbered by their attempt
+func attemptsError(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	attempts := make([]error, len(errs))
+	for i, err := range errs {
+		attempts[i] = fmt.Errorf("attempt %d: %w", i+1, err)
+	}
+	return fmt.Errorf("%d init attempts failed:\n%w", len(errs), errors.Join(attempts...))
+}
@@ -0,0 +1,170 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelInit(t *testing.T) {
+	c := service.NewContainer()
+	c.SetInitConcurrency(2)
+
+	var current, maxConcurrent atomic.Int32
+	for i := 0; i < 6; i++ {
+		service.New(fmt.Sprintf("s%d", i)).
+			Init(func(ctx context.Context) error {
+				n := current.Add(1)
+				defer current.Add(-1)
+				for {
+					m := maxConcurrent.Load()
+					if n <= m || maxConcurrent.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			}).
+			Run(func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}).
+			Register(c)
+	}
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.StopAll()
+	c.WaitAllStopped()
+
+	assert.Equal(t, int32(2), maxConcurrent.Load())
+}
+
+func TestParallelInitRespectsDependencies(t *testing.T) {
+	c := service.NewContainer()
+	c.SetInitConcurrency(10)
+	log := &eventLog{}
+
+	loggingService(log, "api").DependsOn("db").Register(c)
+	service.New("db").
+		Init(func(ctx context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			log.add("init db")
+			return nil
+		}).
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.StopAll()
+	c.WaitAllStopped()
+
+	assert.Equal(t, []string{"init db", "init api", "stop api"}, log.get())
+}
+
+func TestParallelInitJoinsErrors(t *testing.T) {
+	c := service.NewContainer()
+	c.SetInitConcurrency(3)
+
+	err1 := errors.New("first")
+	err2 := errors.New("second")
+	s1 := &testService{Name: "s1", ErrorDuringInit: err1}
+	s2 := &testService{Name: "s2", ErrorDuringInit: err2}
+	s3 := &testService{Name: "s3"}
+	c.Register(s1)
+	c.Register(s2)
+	c.Register(s3, service.DependsOn(s1.String()))
+
+	err := c.StartAll(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, err1)
+	assert.ErrorIs(t, err, err2)
+
+	c.WaitAllStopped()
+	assertServiceNeverStarted(t, s3)
+}
+
+func TestInitTimeout(t *testing.T) {
+	c := service.NewContainer()
+	service.New("db").
+		InitTimeout(10 * time.Millisecond).
+		Init(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestInitRetry(t *testing.T) {
+	c := service.NewContainer()
+	attempts := 0
+	service.New("db").
+		InitRetry(service.InitRetryPolicy{Backoff: fastBackoff, MaxAttempts: 5}).
+		Init(func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("connection refused")
+			}
+			return nil
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	c.StopAll()
+	c.WaitAllStopped()
+}
+
+func TestInitRetryMaxAttempts(t *testing.T) {
+	c := service.NewContainer()
+	initErr := errors.New("connection refused")
+	attempts := 0
+	service.New("db").
+		InitRetry(service.InitRetryPolicy{Backoff: fastBackoff, MaxAttempts: 3}).
+		Init(func(ctx context.Context) error {
+			attempts++
+			return initErr
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.ErrorIs(t, err, initErr)
+	assert.Equal(t, "failed to init service db: 3 init attempts failed:\n"+
+		"attempt 1: connection refused\n"+
+		"attempt 2: connection refused\n"+
+		"attempt 3: connection refused", err.Error())
+}
+
+func TestInitRetryMaxElapsed(t *testing.T) {
+	c := service.NewContainer()
+	attempts := 0
+	service.New("db").
+		InitRetry(service.InitRetryPolicy{Backoff: service.Backoff{Initial: 20 * time.Millisecond, Multiplier: 1}, MaxElapsed: 50 * time.Millisecond}).
+		Init(func(ctx context.Context) error {
+			attempts++
+			return errors.New("connection refused")
+		}).
+		Register(c)
+
+	start := time.Now()
+	err := c.StartAll(context.Background())
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, 3, attempts)
+}
@@ -12,12 +12,42 @@ type Runner interface {
 }
 
 // Initer can be optionally implemented for services that need to run initial startup code
-// All init methods of registered services are executed sequentially
+// All init methods of registered services are executed sequentially, see Container.SetInitConcurrency() to run them in parallel
 // When Init() returns an error, no further services are executed and the application shuts down
 type Initer interface {
 	Init(ctx context.Context) error
 }
 
+// Stopper can be optionally implemented for services that need explicit code to shut down, e.g. http.Server.Shutdown()
+// Stop is called when the container stops the service, right before the context passed to Run is canceled.
+// The context passed to Stop expires after the stop timeout of the service.
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// Readier can be optionally implemented for services that need time after Run was called before they can serve, e.g. to bind a listener
+// Ready is called after Run was started and must block until the service is ready or ctx is done.
+// Services depending on this service are only started after Ready returned without error.
+// Services that do not implement Readier are ready as soon as Run was called.
+type Readier interface {
+	Ready(ctx context.Context) error
+}
+
+// HealthChecker can be optionally implemented for services that can report their health
+// Health is called periodically while the service is running, see Container.SetHealthInterval()
+// Return an error wrapping ErrDegraded for services that still work with limited functionality.
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// Closer can be optionally implemented to release resources acquired in Init.
+// Close is called after all services stopped, in reverse order of initialization.
+// When StartAll fails, all services that were successfully initialized are closed as well.
+// See Container.SetCloseTimeout() to limit the time of each Close call.
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
 type Waiter interface {
 	wait()
 }
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+type TickFunc func(ctx context.Context) error
+
+// OverlapPolicy defines what happens when a tick is due while the previous tick is still running
+type OverlapPolicy int
+
+const (
+	// SkipOverlapping skips ticks that are due while the previous tick is still running, which is the default
+	SkipOverlapping OverlapPolicy = iota
+	// QueueOverlapping runs one tick that was due while the previous tick was running right after it, further ticks are skipped
+	QueueOverlapping
+)
+
+// TickErrorPolicy defines how errors returned by a tick are handled
+type TickErrorPolicy int
+
+const (
+	// LogTickErrors logs errors and keeps the job running, which is the default
+	LogTickErrors TickErrorPolicy = iota
+	// FailAfterTickErrors logs errors and fails the job after MaxTickErrors consecutive errors
+	FailAfterTickErrors
+	// FailOnTickError fails the job with the first error
+	FailOnTickError
+)
+
+// JobOption configures a periodic job, see Every
+type JobOption func(j *job)
+
+// RunOnStart runs the first tick right after the job started instead of after the first interval
+func RunOnStart() JobOption {
+	return func(j *job) {
+		j.runOnStart = true
+	}
+}
+
+// Jitter randomizes each interval by up to the given fraction, e.g. 0.1 for +/- 10%
+func Jitter(fraction float64) JobOption {
+	return func(j *job) {
+		j.jitter = fraction
+	}
+}
+
+// Overlap sets the OverlapPolicy of the job
+func Overlap(policy OverlapPolicy) JobOption {
+	return func(j *job) {
+		j.overlap = policy
+	}
+}
+
+// TickTimeout limits the time of each tick, 0 means no limit which is the default
+func TickTimeout(timeout time.Duration) JobOption {
+	return func(j *job) {
+		j.tickTimeout = timeout
+	}
+}
+
+// OnTickError sets the TickErrorPolicy of the job
+func OnTickError(policy TickErrorPolicy) JobOption {
+	return func(j *job) {
+		j.onError = policy
+	}
+}
+
+// MaxTickErrors sets the number of consecutive errors after which the job fails with FailAfterTickErrors, defaults to 3
+func MaxTickErrors(n int) JobOption {
+	return func(j *job) {
+		j.maxErrors = n
+	}
+}
+
+// JobLogger sets the logger for skipped ticks and tick errors, defaults to the logger of the container
+func JobLogger(logger *slog.Logger) JobOption {
+	return func(j *job) {
+		j.logger = logger
+	}
+}
+
+type job struct {
+	name        string
+	interval    time.Duration
+	tick        TickFunc
+	runOnStart  bool
+	jitter      float64
+	overlap     OverlapPolicy
+	tickTimeout time.Duration
+	onError     TickErrorPolicy
+	maxErrors   int
+	logger      *slog.Logger
+
+	mu sync.Mutex
+	// Number of all ticks and failed ticks, reported by health
+	ticks, failedTicks int
+	// Consecutive failed ticks and the last error, reset by a successful tick
+	errCount int
+	lastErr  error
+}
+
+// Every returns a Builder for a service that calls fn periodically with the given interval until it is stopped.
+// The health of the job is degraded while its last tick failed, see SetHealthInterval.
+// Use the Builder to add further service options, e.g. DependsOn or Restart, or call Build to get the Runner:
+//
+//	service.Every("cleanup", time.Minute, cleanup, service.RunOnStart()).Register(c)
+func Every(name string, interval time.Duration, fn TickFunc, opts ...JobOption) *Builder {
+	if interval <= 0 {
+		panic(fmt.Sprintf("interval of job '%s' must be positive", name))
+	}
+	j := &job{
+		name:      name,
+		interval:  interval,
+		tick:      fn,
+		maxErrors: 3,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return New(name).Run(j.run).Health(j.health)
+}
+
+// health reports the job as degraded with the number of failed ticks while the last tick failed
+func (j *job) health(ctx context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.errCount == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %d consecutive ticks failed, %d of %d ticks failed in total, last error: %w", ErrDegraded, j.errCount, j.failedTicks, j.ticks, j.lastErr)
+}
+
+func (j *job) run(ctx context.Context) error {
+	// Stop the scheduler as well when a tick fails the job
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// A tick is only sent while run is waiting for it, unless one tick can be queued
+	buffer := 0
+	if j.overlap == QueueOverlapping {
+		buffer = 1
+	}
+	ticks := make(chan struct{}, buffer)
+	go j.schedule(ctx, ticks)
+
+	j.mu.Lock()
+	j.errCount, j.lastErr = 0, nil
+	j.mu.Unlock()
+	if j.runOnStart {
+		if err := j.handle(ctx); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-ticks:
+			if err := j.handle(ctx); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// schedule sends a tick after each interval until ctx is done
+func (j *job) schedule(ctx context.Context, ticks chan<- struct{}) {
+	timer := time.NewTimer(j.next())
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			select {
+			case ticks <- struct{}{}:
+			default:
+				j.log(ctx).Debug("Skipped tick, previous tick still running", "name", j.name)
+			}
+			timer.Reset(j.next())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// log returns the logger of the job or the logger of the container
+func (j *job) log(ctx context.Context) *slog.Logger {
+	if j.logger != nil {
+		return j.logger
+	}
+	return contextLogger(ctx)
+}
+
+// next returns the interval until the next tick including jitter
+func (j *job) next() time.Duration {
+	d := float64(j.interval)
+	if j.jitter > 0 {
+		d += d * j.jitter * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(d)
+}
+
+// handle runs a single tick and returns an error when the job must fail according to the TickErrorPolicy
+func (j *job) handle(ctx context.Context) error {
+	tickCtx := ctx
+	if j.tickTimeout > 0 {
+		var cancel context.CancelFunc
+		tickCtx, cancel = context.WithTimeout(ctx, j.tickTimeout)
+		defer cancel()
+	}
+	err := j.tick(tickCtx)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.ticks++
+	if err == nil || ctx.Err() != nil {
+		j.errCount, j.lastErr = 0, nil
+		return nil
+	}
+
+	j.failedTicks++
+	j.errCount++
+	j.lastErr = err
+	switch {
+	case j.onError == FailOnTickError:
+		return err
+	case j.onError == FailAfterTickErrors && j.errCount >= j.maxErrors:
+		return fmt.Errorf("%d consecutive ticks failed, last error: %w", j.errCount, err)
+	}
+	j.log(ctx).Warn("Tick failed", "name", j.name, "error", err, "consecutiveErrors", j.errCount, "failedTicks", j.failedTicks)
+	return nil
+}
@@ -0,0 +1,235 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEvery(t *testing.T) {
+	c := service.NewContainer()
+	var ticks atomic.Int32
+	service.Every("cleanup", 5*time.Millisecond, func(ctx context.Context) error {
+		ticks.Add(1)
+		return nil
+	}, service.Jitter(0.2)).Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return ticks.Load() >= 3
+	}, time.Second, time.Millisecond)
+	c.StopAll()
+	c.WaitAllStopped()
+	assert.Len(t, c.ServiceErrors(), 0)
+}
+
+func TestEveryRunOnStart(t *testing.T) {
+	c := service.NewContainer()
+	ticked := make(chan struct{}, 1)
+	service.Every("cleanup", time.Hour, func(ctx context.Context) error {
+		ticked <- struct{}{}
+		return nil
+	}, service.RunOnStart()).Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	select {
+	case <-ticked:
+	case <-time.After(time.Second):
+		t.Fatal("first tick did not run on start")
+	}
+	c.StopAll()
+	c.WaitAllStopped()
+}
+
+func TestEveryOverlap(t *testing.T) {
+	for _, tc := range []struct {
+		policy service.OverlapPolicy
+		queued bool
+	}{
+		{service.SkipOverlapping, false},
+		{service.QueueOverlapping, true},
+	} {
+		c := service.NewContainer()
+		var ticks atomic.Int32
+		release := make(chan struct{})
+		second := make(chan time.Time, 1)
+		service.Every("slow", 50*time.Millisecond, func(ctx context.Context) error {
+			switch ticks.Add(1) {
+			case 1:
+				<-release
+			case 2:
+				second <- time.Now()
+			}
+			return nil
+		}, service.RunOnStart(), service.Overlap(tc.policy)).Register(c)
+
+		err := c.StartAll(context.Background())
+		require.NoError(t, err)
+		// Ticks at 50ms and 100ms are due while the first tick is running, the next one at 150ms
+		time.Sleep(120 * time.Millisecond)
+		released := time.Now()
+		close(release)
+		delay := (<-second).Sub(released)
+		if tc.queued {
+			assert.Less(t, delay, 15*time.Millisecond)
+		} else {
+			assert.Greater(t, delay, 15*time.Millisecond)
+		}
+		c.StopAll()
+		c.WaitAllStopped()
+	}
+}
+
+func TestEveryTickTimeout(t *testing.T) {
+	c := service.NewContainer()
+	service.Every("slow", time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, service.TickTimeout(5*time.Millisecond), service.OnTickError(service.FailOnTickError)).Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.WaitAllStopped()
+	assert.ErrorIs(t, c.ServiceErrors()["slow"], context.DeadlineExceeded)
+}
+
+func TestEveryTickErrors(t *testing.T) {
+	tickErr := errors.New("cleanup failed")
+	for _, tc := range []struct {
+		policy service.TickErrorPolicy
+		fails  bool
+		ticks  int32
+	}{
+		{service.LogTickErrors, false, 5},
+		{service.FailAfterTickErrors, true, 3},
+		{service.FailOnTickError, true, 1},
+	} {
+		c := service.NewContainer()
+		var ticks atomic.Int32
+		service.Every("cleanup", time.Millisecond, func(ctx context.Context) error {
+			if ticks.Add(1) > 5 {
+				<-ctx.Done()
+				return nil
+			}
+			return tickErr
+		}, service.OnTickError(tc.policy)).Register(c)
+
+		err := c.StartAll(context.Background())
+		require.NoError(t, err)
+		if tc.fails {
+			c.WaitAllStopped()
+			assert.ErrorIs(t, c.ServiceErrors()["cleanup"], tickErr)
+		} else {
+			require.Eventually(t, func() bool {
+				return ticks.Load() > 5
+			}, time.Second, time.Millisecond)
+			c.StopAll()
+			c.WaitAllStopped()
+			assert.Len(t, c.ServiceErrors(), 0)
+		}
+		assert.Equal(t, tc.ticks, min(ticks.Load(), 5), tc.policy)
+	}
+}
+
+func TestEveryTickErrorsInHealth(t *testing.T) {
+	c := service.NewContainer()
+	c.SetHealthInterval(5 * time.Millisecond)
+	var ticks atomic.Int32
+	service.Every("cleanup", time.Millisecond, func(ctx context.Context) error {
+		if ticks.Add(1) > 2 {
+			<-ctx.Done()
+			return nil
+		}
+		return errors.New("cleanup failed")
+	}).Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return c.Health().Services["cleanup"].Error == "degraded: 2 consecutive ticks failed, 2 of 2 ticks failed in total, last error: cleanup failed"
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, service.Degraded, c.Health().Status)
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
+
+func TestEveryRestartStopsScheduler(t *testing.T) {
+	c := service.NewContainer()
+	var ticks atomic.Int32
+	service.Every("cleanup", time.Millisecond, func(ctx context.Context) error {
+		ticks.Add(1)
+		return errors.New("cleanup failed")
+	}, service.OnTickError(service.FailOnTickError)).
+		Restart(service.RestartPolicy{Mode: service.RestartOnFailure, Backoff: service.Backoff{Initial: time.Millisecond, Max: time.Millisecond}}).
+		Register(c)
+
+	goroutines := runtime.NumGoroutine()
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return ticks.Load() >= 50
+	}, 5*time.Second, time.Millisecond)
+	// Without leaks only the run and scheduler go-routines of the job are left
+	assert.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= goroutines+10
+	}, time.Second, time.Millisecond)
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
+
+func TestEveryLogsWithContainerLogger(t *testing.T) {
+	c := service.NewContainer()
+	var buf bytes.Buffer
+	c.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	var ticks atomic.Int32
+	service.Every("cleanup", time.Millisecond, func(ctx context.Context) error {
+		ticks.Add(1)
+		return errors.New("cleanup failed")
+	}).Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return ticks.Load() >= 2
+	}, time.Second, time.Millisecond)
+	c.StopAll()
+	c.WaitAllStopped()
+
+	assert.Contains(t, buf.String(), `msg="Tick failed" name=cleanup error="cleanup failed"`)
+}
+
+func TestEveryAddToRunningContainer(t *testing.T) {
+	c := service.NewContainer()
+	c.Register(blockingService("db"))
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+
+	var ticks atomic.Int32
+	tick := func(ctx context.Context) error {
+		ticks.Add(1)
+		return nil
+	}
+	err = c.Add(context.Background(), service.Every("cleanup", time.Millisecond, tick).Build(), service.DependsOn("db"))
+	require.NoError(t, err)
+	err = service.Every("compact", time.Millisecond, tick).DependsOn("db").Add(context.Background(), c)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return ticks.Load() >= 4
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 3, c.RunningCount())
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
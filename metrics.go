@@ -0,0 +1,73 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// metric is a single metric family in the Prometheus text format
+type metric struct {
+	name  string
+	help  string
+	kind  string
+	value func(s ServiceStatus) float64
+}
+
+var metrics = []metric{
+	{"service_running", "Whether the service is running (1) or not (0).", "gauge", func(s ServiceStatus) float64 {
+		if s.State == StateRunning {
+			return 1
+		}
+		return 0
+	}},
+	{"service_init_duration_seconds", "Time the last Init of the service took.", "gauge", func(s ServiceStatus) float64 {
+		return s.InitDuration.Seconds()
+	}},
+	{"service_stop_duration_seconds", "Time the last stop of the service took.", "gauge", func(s ServiceStatus) float64 {
+		return s.StopDuration.Seconds()
+	}},
+	{"service_uptime_seconds", "Time since the service is running, 0 if it is not running.", "gauge", func(s ServiceStatus) float64 {
+		return s.Uptime.Seconds()
+	}},
+	{"service_restarts_total", "Number of restarts of the service.", "counter", func(s ServiceStatus) float64 {
+		return float64(s.Restarts)
+	}},
+	{"service_failures_total", "Number of errors returned by Init, Run, Stop and Close of the service.", "counter", func(s ServiceStatus) float64 {
+		return float64(s.Failures)
+	}},
+}
+
+// WriteMetrics writes the metrics of all services in the Prometheus text format, each labeled by service name
+func (c *Container) WriteMetrics(w io.Writer) error {
+	status := c.Status()
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(bw, "# TYPE %s %s\n", m.name, m.kind)
+		for _, s := range status {
+			fmt.Fprintf(bw, "%s{service=\"%s\"} %g\n", m.name, escapeLabel(s.Name), m.value(s))
+		}
+	}
+	return bw.Flush()
+}
+
+// MetricsHandler serves the metrics of all services of the container in the Prometheus text format.
+// Register it e.g. at /metrics of your HTTP server.
+func MetricsHandler(c *Container) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := c.WriteMetrics(w); err != nil {
+			c.logger().Warn("Failed to write metrics", "error", err)
+		}
+	})
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value as required by the Prometheus text format
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,51 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	c := service.NewContainer()
+	c.SetStrategy(service.OneForOne)
+	c.Register(blockingService("db"))
+	service.New(`push "metrics"`).
+		Restart(service.RestartPolicy{Mode: service.RestartOnFailure, MaxRestarts: 2, Backoff: fastBackoff}).
+		Run(func(ctx context.Context) error {
+			return errors.New("push failed")
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return c.Status()[1].State == service.StateFailed
+	}, time.Second, time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	service.MetricsHandler(c).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "# TYPE service_running gauge\n")
+	assert.Contains(t, body, "service_running{service=\"db\"} 1\n")
+	assert.Contains(t, body, "service_running{service=\"push \\\"metrics\\\"\"} 0\n")
+	assert.Contains(t, body, "# TYPE service_restarts_total counter\n")
+	assert.Contains(t, body, "service_restarts_total{service=\"push \\\"metrics\\\"\"} 2\n")
+	assert.Contains(t, body, "service_failures_total{service=\"push \\\"metrics\\\"\"} 3\n")
+	assert.Contains(t, body, "service_uptime_seconds{service=\"db\"} ")
+	assert.Contains(t, body, "service_init_duration_seconds{service=\"db\"} ")
+
+	c.StopAll()
+	c.WaitAllStopped()
+	var out strings.Builder
+	require.NoError(t, c.WriteMetrics(&out))
+	assert.Contains(t, out.String(), "service_running{service=\"db\"} 0\n")
+	assert.NotContains(t, out.String(), "service_stop_duration_seconds{service=\"db\"} 0\n")
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"slices"
+	"time"
+)
+
+// Phase of the service lifecycle
+type Phase string
+
+const (
+	PhaseInit  Phase = "init"
+	PhaseRun   Phase = "run"
+	PhaseStop  Phase = "stop"
+	PhaseClose Phase = "close"
+)
+
+// LifecycleEvent describes a single step in the lifecycle of a service
+type LifecycleEvent struct {
+	// Service name
+	Service string
+	Phase   Phase
+	// Duration of the phase, set when the phase is finished
+	Duration time.Duration
+	// Err returned by the service, if any
+	Err error
+}
+
+// LifecycleObserver gets notified about the lifecycle of all services inside a container, e.g. to collect metrics.
+// Methods are called synchronously from the go-routine of the service and must not block.
+// Use ObserverFuncs to only implement some of the methods.
+type LifecycleObserver interface {
+	// OnBeforeInit is called before a service is initialized
+	OnBeforeInit(e LifecycleEvent)
+	// OnAfterInit is called after a service is initialized, Err is set when Init failed
+	OnAfterInit(e LifecycleEvent)
+	// OnBeforeRun is called before Run of a service is called, also when the service is restarted
+	OnBeforeRun(e LifecycleEvent)
+	// OnServiceStopped is called whenever Run of a service returned, Duration is the time Run was executed
+	OnServiceStopped(e LifecycleEvent)
+	// OnServiceFailed is called when Init, Run or Stop of a service returned an error
+	OnServiceFailed(e LifecycleEvent)
+}
+
+var _ LifecycleObserver = ObserverFuncs{}
+
+// ObserverFuncs implements LifecycleObserver with optional functions
+type ObserverFuncs struct {
+	BeforeInit     func(e LifecycleEvent)
+	AfterInit      func(e LifecycleEvent)
+	BeforeRun      func(e LifecycleEvent)
+	ServiceStopped func(e LifecycleEvent)
+	ServiceFailed  func(e LifecycleEvent)
+}
+
+func (o ObserverFuncs) OnBeforeInit(e LifecycleEvent) {
+	if o.BeforeInit != nil {
+		o.BeforeInit(e)
+	}
+}
+
+func (o ObserverFuncs) OnAfterInit(e LifecycleEvent) {
+	if o.AfterInit != nil {
+		o.AfterInit(e)
+	}
+}
+
+func (o ObserverFuncs) OnBeforeRun(e LifecycleEvent) {
+	if o.BeforeRun != nil {
+		o.BeforeRun(e)
+	}
+}
+
+func (o ObserverFuncs) OnServiceStopped(e LifecycleEvent) {
+	if o.ServiceStopped != nil {
+		o.ServiceStopped(e)
+	}
+}
+
+func (o ObserverFuncs) OnServiceFailed(e LifecycleEvent) {
+	if o.ServiceFailed != nil {
+		o.ServiceFailed(e)
+	}
+}
+
+// AddObserver registers an observer that gets notified about the lifecycle of all services
+func (c *Container) AddObserver(o LifecycleObserver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observers = append(c.observers, o)
+}
+
+// notify calls f for all registered observers
+func (c *Container) notify(f func(o LifecycleObserver)) {
+	c.mu.Lock()
+	observers := slices.Clone(c.observers)
+	c.mu.Unlock()
+	for _, o := range observers {
+		f(o)
+	}
+}
+
+// onRegistered is called after a service was registered
+func (c *Container) onRegistered(s *serviceInfo) {
+	c.publish(Event{Type: EventRegistered, Service: s.name})
+}
+
+// onInit is called before a service Init method is called
+func (c *Container) onInit(s *serviceInfo) {
+	e := LifecycleEvent{Service: s.name, Phase: PhaseInit}
+	c.publish(Event{Type: EventInitStarted, Service: s.name, Phase: PhaseInit})
+	c.notify(func(o LifecycleObserver) { o.OnBeforeInit(e) })
+}
+
+// onInitDone is called after a service was initialized
+func (c *Container) onInitDone(s *serviceInfo, d time.Duration, err error) {
+	e := LifecycleEvent{Service: s.name, Phase: PhaseInit, Duration: d, Err: err}
+	c.publish(Event{Type: EventInitFinished, Service: s.name, Phase: PhaseInit, Duration: d, Err: err})
+	c.notify(func(o LifecycleObserver) { o.OnAfterInit(e) })
+	if err != nil {
+		c.onFailed(e)
+	}
+}
+
+// onRun is called before a service Run method is called
+func (c *Container) onRun(s *serviceInfo) {
+	e := LifecycleEvent{Service: s.name, Phase: PhaseRun}
+	c.publish(Event{Type: EventRunStarted, Service: s.name, Phase: PhaseRun})
+	c.notify(func(o LifecycleObserver) { o.OnBeforeRun(e) })
+}
+
+// onStopped is called after a service Run method returned
+func (c *Container) onStopped(s *serviceInfo, d time.Duration, err error) {
+	e := LifecycleEvent{Service: s.name, Phase: PhaseRun, Duration: d, Err: err}
+	c.publish(Event{Type: EventStopped, Service: s.name, Phase: PhaseRun, Duration: d, Err: err})
+	c.notify(func(o LifecycleObserver) { o.OnServiceStopped(e) })
+}
+
+// onFailed is called when a service returned an error in any phase
+func (c *Container) onFailed(e LifecycleEvent) {
+	c.mu.Lock()
+	if rc, ok := c.runContexts[e.Service]; ok {
+		rc.failures++
+	}
+	c.mu.Unlock()
+	c.publish(Event{Type: EventFailed, Service: e.Service, Phase: e.Phase, Duration: e.Duration, Err: e.Err})
+	c.notify(func(o LifecycleObserver) { o.OnServiceFailed(e) })
+}
@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestObserver(t *testing.T) {
+	log := &eventLog{}
+	record := func(name string) func(e service.LifecycleEvent) {
+		return func(e service.LifecycleEvent) {
+			log.add(fmt.Sprintf("%s %s %s %v", name, e.Service, e.Phase, e.Err))
+		}
+	}
+
+	c := service.NewContainer()
+	c.AddObserver(service.ObserverFuncs{
+		BeforeInit:     record("before-init"),
+		AfterInit:      record("after-init"),
+		BeforeRun:      record("before-run"),
+		ServiceStopped: record("stopped"),
+		ServiceFailed:  record("failed"),
+	})
+	service.New("db").
+		Init(func(ctx context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}).
+		Run(func(ctx context.Context) error {
+			return errors.New("connection lost")
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.WaitAllStopped()
+
+	assert.Equal(t, []string{
+		"before-init db init <nil>",
+		"after-init db init <nil>",
+		"before-run db run <nil>",
+		"stopped db run connection lost",
+		"failed db run connection lost",
+	}, log.get())
+}
+
+func TestObserverDuration(t *testing.T) {
+	var initDuration, runDuration time.Duration
+	c := service.NewContainer()
+	c.AddObserver(service.ObserverFuncs{
+		AfterInit: func(e service.LifecycleEvent) {
+			initDuration = e.Duration
+		},
+		ServiceStopped: func(e service.LifecycleEvent) {
+			runDuration = e.Duration
+		},
+	})
+	service.New("db").
+		Init(func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}).
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	c.StopAll()
+	c.WaitAllStopped()
+
+	assert.GreaterOrEqual(t, initDuration, 10*time.Millisecond)
+	assert.GreaterOrEqual(t, runDuration, 10*time.Millisecond)
+}
+
+func TestObserverInitAndStopFailure(t *testing.T) {
+	var failed []service.LifecycleEvent
+	c := service.NewContainer()
+	c.AddObserver(service.ObserverFuncs{
+		ServiceFailed: func(e service.LifecycleEvent) {
+			failed = append(failed, e)
+		},
+	})
+	service.New("db").
+		Init(func(ctx context.Context) error {
+			return errors.New("no connection")
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.Error(t, err)
+	c.WaitAllStopped()
+	require.Len(t, failed, 1)
+	assert.Equal(t, "db", failed[0].Service)
+	assert.Equal(t, service.PhaseInit, failed[0].Phase)
+	assert.EqualError(t, failed[0].Err, "no connection")
+
+	failed = nil
+	c = service.NewContainer()
+	c.AddObserver(service.ObserverFuncs{
+		ServiceFailed: func(e service.LifecycleEvent) {
+			failed = append(failed, e)
+		},
+	})
+	service.New("db").
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Stop(func(ctx context.Context) error {
+			return errors.New("flush failed")
+		}).
+		Register(c)
+
+	err = c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.StopAll()
+	c.WaitAllStopped()
+	require.Len(t, failed, 1)
+	assert.Equal(t, service.PhaseStop, failed[0].Phase)
+	assert.EqualError(t, failed[0].Err, "flush failed")
+}
@@ -0,0 +1,52 @@
+package service
+
+import "time"
+
+// Option configures how a single service is handled by the Container
+type Option func(s *serviceInfo)
+
+// DependsOn declares that a service depends on the services with the given names.
+// Dependencies are initialized and started before the service and are only stopped after the service stopped.
+func DependsOn(names ...string) Option {
+	return func(s *serviceInfo) {
+		s.dependsOn = append(s.dependsOn, names...)
+	}
+}
+
+// StopTimeout sets the time a service gets to stop gracefully, overriding Container.SetStopTimeout().
+// After the timeout services depending on it are stopped anyway.
+func StopTimeout(timeout time.Duration) Option {
+	return func(s *serviceInfo) {
+		s.stopTimeout = timeout
+	}
+}
+
+// InitTimeout limits the time of each call to Init of a service, 0 means no limit which is the default
+func InitTimeout(timeout time.Duration) Option {
+	return func(s *serviceInfo) {
+		s.initTimeout = timeout
+	}
+}
+
+// InitRetry sets the InitRetryPolicy of a service, by default a failing Init is not retried
+func InitRetry(policy InitRetryPolicy) Option {
+	return func(s *serviceInfo) {
+		s.initRetry = policy
+	}
+}
+
+// Restart sets the RestartPolicy of a service, by default services are never restarted
+func Restart(policy RestartPolicy) Option {
+	return func(s *serviceInfo) {
+		s.restart = policy
+	}
+}
+
+// Critical defines if a failing service affects other services, which is the default.
+// Failures of non-critical services are logged and reported by Container.ServiceErrors(),
+// while all other services keep running. Non-critical services can still be restarted, see Restart().
+func Critical(critical bool) Option {
+	return func(s *serviceInfo) {
+		s.optional = !critical
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned when a service panics during Init, Run, Stop, Close, Ready or Health
+type PanicError struct {
+	// Name of the service that panicked
+	Service string
+	// Value passed to panic()
+	Value any
+	// Stack of the panicking go-routine
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("service %s panicked: %v", e.Service, e.Value)
+}
+
+// Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// SetRepanic defines if recovered panics of services are raised again, which crashes the application.
+// By default panics are turned into a PanicError and handled like any other error returned by the service.
+func (c *Container) SetRepanic(repanic bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.repanic = repanic
+}
+
+// safeCall calls f and turns a panic into a PanicError
+func (c *Container) safeCall(s *serviceInfo, f func() error) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		panicErr := &PanicError{Service: s.name, Value: r, Stack: debug.Stack()}
+		c.logger().Error("Service panicked", "name", s.name, "panic", r, "stack", string(panicErr.Stack))
+		c.mu.Lock()
+		repanic := c.repanic
+		c.mu.Unlock()
+		if repanic {
+			panic(panicErr)
+		}
+		err = panicErr
+	}()
+	return f()
+}
@@ -0,0 +1,108 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPanicInRun(t *testing.T) {
+	c := service.NewContainer()
+	s1 := &testService{Name: "s1"}
+	c.Register(s1)
+	service.New("panicking").Run(func(ctx context.Context) error {
+		panic("something went terribly wrong")
+	}).Register(c)
+
+	shutdownCalled := make(chan struct{})
+	c.OnShutdown(func() {
+		close(shutdownCalled)
+	})
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.WaitAllStopped()
+	<-shutdownCalled
+
+	var panicErr *service.PanicError
+	require.True(t, errors.As(c.ServiceErrors()["panicking"], &panicErr))
+	assert.Equal(t, "panicking", panicErr.Service)
+	assert.Equal(t, "something went terribly wrong", panicErr.Value)
+	assert.Contains(t, string(panicErr.Stack), "panic_test.go")
+	assertServiceStartedAndStopped(t, s1)
+}
+
+func TestPanicInInit(t *testing.T) {
+	c := service.NewContainer()
+	cause := errors.New("nil config")
+	service.New("panicking").Init(func(ctx context.Context) error {
+		panic(cause)
+	}).Register(c)
+
+	err := c.StartAll(context.Background())
+	var panicErr *service.PanicError
+	require.True(t, errors.As(err, &panicErr))
+	assert.ErrorIs(t, err, cause)
+	c.WaitAllStopped()
+}
+
+func TestRepanic(t *testing.T) {
+	c := service.NewContainer()
+	c.SetRepanic(true)
+	service.New("panicking").Init(func(ctx context.Context) error {
+		panic("crash only")
+	}).Register(c)
+
+	assert.Panics(t, func() {
+		_ = c.StartAll(context.Background())
+	})
+}
+
+func TestPanicInReady(t *testing.T) {
+	c := service.NewContainer()
+	service.New("panicking").
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Ready(func(ctx context.Context) error {
+			panic("not ready")
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.WaitAllStopped()
+
+	var panicErr *service.PanicError
+	require.True(t, errors.As(c.ServiceErrors()["panicking"], &panicErr))
+	assert.Equal(t, "not ready", panicErr.Value)
+}
+
+func TestPanicInHealth(t *testing.T) {
+	c := service.NewContainer()
+	c.SetHealthInterval(5 * time.Millisecond)
+	service.New("panicking").
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Health(func(ctx context.Context) error {
+			panic("broken check")
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return c.Health().Services["panicking"].Status == service.Unhealthy
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "service panicking panicked: broken check", c.Health().Services["panicking"].Error)
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// probeResult is the result of a probe for a single service
+type probeResult struct {
+	name string
+	err  error
+	// Excluded results do not affect the probe, e.g. for non-critical services
+	excluded bool
+	// Additional information about a passed probe
+	info string
+}
+
+// liveness reports errors of critical services that failed or hang while stopping longer than their stop timeout
+func (c *Container) liveness() []probeResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	var results []probeResult
+	for _, s := range c.services {
+		result := probeResult{name: s.name, excluded: s.optional}
+		if rc, ok := c.runContexts[s.name]; ok {
+			timeout := c.stopTimeout
+			if s.stopTimeout != 0 {
+				timeout = s.stopTimeout
+			}
+			switch {
+			case rc.state == StateFailed:
+				result.err = errors.Join(rc.initErr, rc.err)
+				if result.err == nil {
+					result.err = errors.New("service failed")
+				}
+			case rc.state == StateStopping && timeout != 0 && now.Sub(rc.since[StateStopping]) > timeout:
+				result.err = fmt.Errorf("hangs in state %s for %v", rc.state, now.Sub(rc.since[StateStopping]).Round(time.Millisecond))
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// readiness reports errors of critical services that are not running and ready
+func (c *Container) readiness() []probeResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var results []probeResult
+	if c.runCtx == nil {
+		results = append(results, probeResult{name: "container", err: errors.New("not started")})
+	} else if c.runCtx.Err() != nil {
+		results = append(results, probeResult{name: "container", err: errors.New("stopping")})
+	}
+	for _, s := range c.services {
+		result := probeResult{name: s.name, excluded: s.optional}
+		rc, ok := c.runContexts[s.name]
+		switch {
+		case !ok:
+			result.err = errors.New("not started")
+		case rc.state != StateRunning:
+			result.err = fmt.Errorf("not running, state %s", rc.state)
+		default:
+			select {
+			case <-rc.ready:
+				if rc.readyErr != nil {
+					result.err = rc.readyErr
+				}
+			default:
+				result.err = errors.New("not ready")
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// healthiness reports errors of unhealthy services based on the last health checks, see Container.Health
+func (c *Container) healthiness() []probeResult {
+	report := c.Health()
+	var results []probeResult
+	for _, s := range c.Status() {
+		health, ok := report.Services[s.Name]
+		if !ok {
+			continue
+		}
+		result := probeResult{name: s.Name, excluded: !s.Critical}
+		msg := string(health.Status)
+		if health.Error != "" {
+			msg += ": " + health.Error
+		}
+		switch health.Status {
+		case Degraded:
+			result.info = msg
+		case Unhealthy:
+			result.err = errors.New(msg)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// LivezHandler serves a liveness probe that fails when a critical service failed or hangs while stopping.
+// Add the query parameter ?verbose to get the result per service.
+func LivezHandler(c *Container) http.Handler {
+	return probeHandler("livez", c.liveness)
+}
+
+// ReadyzHandler serves a readiness probe that fails until all critical services are running and ready, see Readier.
+// Add the query parameter ?verbose to get the result per service.
+func ReadyzHandler(c *Container) http.Handler {
+	return probeHandler("readyz", c.readiness)
+}
+
+// HealthzHandler serves a health probe that fails when a critical service is unhealthy, see Container.Health.
+// Add the query parameter ?verbose to get the result per service.
+func HealthzHandler(c *Container) http.Handler {
+	return probeHandler("healthz", c.healthiness)
+}
+
+// ProbeHandler serves the /livez, /readyz and /healthz probes of the container
+func ProbeHandler(c *Container) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /livez", LivezHandler(c))
+	mux.Handle("GET /readyz", ReadyzHandler(c))
+	mux.Handle("GET /healthz", HealthzHandler(c))
+	return mux
+}
+
+// probeHandler writes the results of a probe in the plain text format of Kubernetes probes
+func probeHandler(probe string, check func() []probeResult) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results := check()
+		failed := false
+		for _, result := range results {
+			if result.err != nil && !result.excluded {
+				failed = true
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		status := http.StatusOK
+		if failed {
+			status = http.StatusServiceUnavailable
+		}
+		w.WriteHeader(status)
+		if !failed && !r.URL.Query().Has("verbose") {
+			fmt.Fprint(w, "ok\n")
+			return
+		}
+
+		var b strings.Builder
+		for _, result := range results {
+			switch {
+			case result.err != nil && result.excluded:
+				fmt.Fprintf(&b, "[+]%s excluded: %v\n", result.name, result.err)
+			case result.err != nil:
+				fmt.Fprintf(&b, "[-]%s failed: %v\n", result.name, result.err)
+			case result.info != "":
+				fmt.Fprintf(&b, "[+]%s ok, %s\n", result.name, result.info)
+			default:
+				fmt.Fprintf(&b, "[+]%s ok\n", result.name)
+			}
+		}
+		if failed {
+			fmt.Fprintf(&b, "%s check failed\n", probe)
+		} else {
+			fmt.Fprintf(&b, "%s check passed\n", probe)
+		}
+		fmt.Fprint(w, b.String())
+	})
+}
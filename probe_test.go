@@ -0,0 +1,158 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadyz(t *testing.T) {
+	c := service.NewContainer()
+	h := service.ProbeHandler(c)
+	readyCh := make(chan struct{})
+	c.Register(blockingService("db"))
+	service.New("api").
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Ready(func(ctx context.Context) error {
+			<-readyCh
+			return nil
+		}).
+		Register(c)
+	service.New("metrics").
+		Critical(false).
+		Run(func(ctx context.Context) error {
+			return errors.New("push failed")
+		}).
+		Register(c)
+
+	rec := adminRequest(h, "GET", "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "[-]container failed: not started\n")
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		state, _ := c.ServiceState("metrics")
+		return state == service.StateFailed
+	}, time.Second, time.Millisecond)
+
+	rec = adminRequest(h, "GET", "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "[+]db ok\n"+
+		"[-]api failed: not ready\n"+
+		"[+]metrics excluded: not running, state failed\n"+
+		"readyz check failed\n", rec.Body.String())
+
+	close(readyCh)
+	assert.Eventually(t, func() bool {
+		return adminRequest(h, "GET", "/readyz").Code == http.StatusOK
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "ok\n", adminRequest(h, "GET", "/readyz").Body.String())
+	assert.Contains(t, adminRequest(h, "GET", "/readyz?verbose").Body.String(), "readyz check passed\n")
+
+	c.StopAll()
+	c.WaitAllStopped()
+	rec = adminRequest(h, "GET", "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "[-]container failed: stopping\n")
+}
+
+func TestLivez(t *testing.T) {
+	c := service.NewContainer()
+	c.SetStrategy(service.OneForOne)
+	h := service.ProbeHandler(c)
+	failCh := make(chan struct{})
+	stuck := make(chan struct{})
+	defer close(stuck)
+	c.Register(blockingService("db"))
+	service.New("worker").
+		Run(func(ctx context.Context) error {
+			<-failCh
+			return errors.New("queue closed")
+		}).
+		Register(c)
+	service.New("stuck").
+		StopTimeout(10 * time.Millisecond).
+		Run(func(ctx context.Context) error {
+			<-stuck
+			return nil
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	rec := adminRequest(h, "GET", "/livez?verbose")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "[+]db ok\n[+]worker ok\n[+]stuck ok\nlivez check passed\n", rec.Body.String())
+
+	close(failCh)
+	assert.Eventually(t, func() bool {
+		return adminRequest(h, "GET", "/livez").Code == http.StatusServiceUnavailable
+	}, time.Second, time.Millisecond)
+	assert.Contains(t, adminRequest(h, "GET", "/livez").Body.String(), "[-]worker failed: queue closed\n")
+
+	go func() {
+		_ = c.StopService(context.Background(), "stuck")
+	}()
+	assert.Eventually(t, func() bool {
+		body := adminRequest(h, "GET", "/livez").Body.String()
+		return strings.Contains(body, "[-]stuck failed: hangs in state stopping for ")
+	}, time.Second, time.Millisecond)
+}
+
+func TestHealthz(t *testing.T) {
+	c := service.NewContainer()
+	c.SetHealthInterval(5 * time.Millisecond)
+	h := service.ProbeHandler(c)
+	var cacheErr, dbErr atomic.Value
+	cacheErr.Store(fmt.Errorf("high latency: %w", service.ErrDegraded))
+	dbErr.Store(errors.New("ok"))
+	service.New("db").
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Health(func(ctx context.Context) error {
+			if err := dbErr.Load().(error); err.Error() != "ok" {
+				return err
+			}
+			return nil
+		}).
+		Register(c)
+	service.New("cache").
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Health(func(ctx context.Context) error {
+			return cacheErr.Load().(error)
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	defer c.WaitAllStopped()
+	defer c.StopAll()
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(adminRequest(h, "GET", "/healthz?verbose").Body.String(), "[+]cache ok, degraded: high latency: degraded\n")
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, http.StatusOK, adminRequest(h, "GET", "/healthz").Code)
+
+	dbErr.Store(errors.New("connection lost"))
+	assert.Eventually(t, func() bool {
+		return adminRequest(h, "GET", "/healthz").Code == http.StatusServiceUnavailable
+	}, time.Second, time.Millisecond)
+	assert.Contains(t, adminRequest(h, "GET", "/healthz").Body.String(), "[-]db failed: unhealthy: connection lost\n")
+}
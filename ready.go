@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// awaitReady marks the service as ready as soon as its Ready method returns.
+// A failed Ready is handled like a failed Run, unless the service is stopped on purpose.
+func (c *Container) awaitReady(rc *runContext) {
+	readier := rc.service.service.(Readier)
+	start := time.Now()
+	err := c.safeCall(rc.service, func() error {
+		return readier.Ready(rc.ctx)
+	})
+	if err == nil {
+		close(rc.ready)
+		c.logger().Info("Service is ready", "name", rc.service.name)
+		return
+	}
+	c.logger().Error("Service failed to get ready", "name", rc.service.name, "error", err)
+	rc.readyErr = fmt.Errorf("service %s failed to get ready: %w", rc.service.name, err)
+	close(rc.ready)
+
+	c.mu.Lock()
+	deliberate := rc.stopped()
+	if !deliberate {
+		rc.err = rc.readyErr
+	}
+	c.mu.Unlock()
+	if !deliberate {
+		c.fail(rc, time.Since(start), rc.readyErr)
+	}
+}
+
+// waitReady blocks until all services are ready, stopped or the timeout is exceeded
+func (c *Container) waitReady(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c.mu.Lock()
+	rcs := make([]*runContext, 0, len(c.order))
+	for _, s := range c.order {
+		// Non-critical services do not delay the start of the container
+		if rc, ok := c.runContexts[s.name]; ok && !s.optional {
+			rcs = append(rcs, rc)
+		}
+	}
+	c.mu.Unlock()
+
+	var errs []error
+	for _, rc := range rcs {
+		select {
+		case <-rc.ready:
+			if rc.readyErr != nil {
+				errs = append(errs, &ServiceError{Service: rc.service.name, Phase: PhaseRun, Err: rc.readyErr})
+			}
+		case <-rc.done:
+			c.mu.Lock()
+			err := rc.err
+			c.mu.Unlock()
+			if err != nil {
+				errs = append(errs, &ServiceError{Service: rc.service.name, Phase: PhaseRun, Err: fmt.Errorf("stopped before it was ready: %w", err)})
+			}
+		case <-ctx.Done():
+			errs = append(errs, &ServiceError{Service: rc.service.name, Phase: PhaseRun, Err: fmt.Errorf("not ready: %w", ctx.Err())})
+			return errors.Join(errs...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isReadier reports if the service implements Readier and actually provides a Ready method
+func isReadier(service Runner) bool {
+	if s, ok := service.(interface{ hasReady() bool }); ok {
+		return s.hasReady()
+	}
+	_, ok := service.(Readier)
+	return ok
+}
+
+// allReady reports if all services are ready
+func allReady(rcs []*runContext) bool {
+	for _, rc := range rcs {
+		select {
+		case <-rc.ready:
+			if rc.readyErr != nil {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// readyService becomes ready after the given delay
+func readyService(log *eventLog, name string, delay time.Duration) *service.Builder {
+	readyCh := make(chan struct{})
+	return service.New(name).
+		Run(func(ctx context.Context) error {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return nil
+			}
+			log.add("ready " + name)
+			close(readyCh)
+			<-ctx.Done()
+			return nil
+		}).
+		Ready(func(ctx context.Context) error {
+			select {
+			case <-readyCh:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+}
+
+func TestDependentStartsWhenReady(t *testing.T) {
+	c := service.NewContainer()
+	log := &eventLog{}
+
+	readyService(log, "db", 20*time.Millisecond).Register(c)
+	readyService(log, "api", 0).DependsOn("db").Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(log.get()) == 2
+	}, time.Second, time.Millisecond)
+	c.StopAll()
+	c.WaitAllStopped()
+
+	assert.Equal(t, []string{"ready db", "ready api"}, log.get())
+}
+
+func TestStartAllWaitsForReady(t *testing.T) {
+	c := service.NewContainer()
+	c.SetReadyTimeout(time.Second)
+	log := &eventLog{}
+
+	readyService(log, "db", 20*time.Millisecond).Register(c)
+	readyService(log, "api", 20*time.Millisecond).DependsOn("db").Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ready db", "ready api"}, log.get())
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
+
+func TestStartAllReadyTimeout(t *testing.T) {
+	c := service.NewContainer()
+	c.SetReadyTimeout(20 * time.Millisecond)
+	log := &eventLog{}
+
+	readyService(log, "slow", time.Second).Register(c)
+
+	err := c.StartAll(context.Background())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	var serviceErr *service.ServiceError
+	require.True(t, errors.As(err, &serviceErr))
+	assert.Equal(t, "slow", serviceErr.Service)
+	assert.Equal(t, service.PhaseRun, serviceErr.Phase)
+	c.WaitAllStopped()
+	assert.Equal(t, 0, c.RunningCount())
+}
+
+// notReadyService runs until it is stopped but fails to get ready
+func notReadyService(name string) *service.Builder {
+	return service.New(name).
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Ready(func(ctx context.Context) error {
+			return errors.New("connection refused")
+		})
+}
+
+func TestFailedReadyStopsContainer(t *testing.T) {
+	c := service.NewContainer()
+	notReadyService("db").Register(c)
+	c.Register(blockingService("other"))
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = c.Wait(ctx)
+	assert.EqualError(t, err, "failed to run service db: service db failed to get ready: connection refused")
+	assert.Equal(t, 0, c.RunningCount())
+}
+
+func TestDependentOfFailedReadyFails(t *testing.T) {
+	c := service.NewContainer()
+	c.SetStrategy(service.OneForOne)
+	notReadyService("db").Register(c)
+	service.New("api").DependsOn("db").Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		state, _ := c.ServiceState("api")
+		return state == service.StateFailed
+	}, time.Second, time.Millisecond)
+
+	errs := c.ServiceErrors()
+	assert.EqualError(t, errs["db"], "service db failed to get ready: connection refused")
+	assert.EqualError(t, errs["api"], "not started, dependency db is not ready")
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
+
+func TestFailedRunCancelsReady(t *testing.T) {
+	for _, critical := range []bool{true, false} {
+		c := service.NewContainer()
+		c.SetStrategy(service.OneForOne)
+		c.Register(blockingService("db"))
+		readyReturned := make(chan struct{})
+		service.New("cache").
+			Critical(critical).
+			Run(func(ctx context.Context) error {
+				return errors.New("connection refused")
+			}).
+			Ready(func(ctx context.Context) error {
+				defer close(readyReturned)
+				<-ctx.Done()
+				return ctx.Err()
+			}).
+			Register(c)
+
+		err := c.StartAll(context.Background())
+		require.NoError(t, err)
+		select {
+		case <-readyReturned:
+		case <-time.After(time.Second):
+			t.Fatalf("Ready of failed service not canceled, critical %v", critical)
+		}
+		assert.Equal(t, 1, c.RunningCount())
+
+		c.StopAll()
+		c.WaitAllStopped()
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RestartMode defines when a service is restarted after Run returned
+type RestartMode int
+
+const (
+	// RestartNever never restarts a service, which is the default
+	RestartNever RestartMode = iota
+	// RestartOnFailure restarts a service when Run returned an error
+	RestartOnFailure
+	// RestartAlways restarts a service whenever Run returned, until the service is stopped
+	RestartAlways
+)
+
+func (m RestartMode) String() string {
+	switch m {
+	case RestartNever:
+		return "never"
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	}
+	return "unknown"
+}
+
+// Backoff calculates exponentially growing delays between attempts
+type Backoff struct {
+	// Initial delay before the first retry, defaults to 100ms
+	Initial time.Duration
+	// Max is the upper limit of the delay, defaults to 30s
+	Max time.Duration
+	// Multiplier the delay grows with every attempt, defaults to 2
+	Multiplier float64
+	// Jitter randomizes each delay by up to the given fraction, e.g. 0.2 for +/- 20%
+	Jitter float64
+}
+
+// maxDelay returns the upper limit of the delay
+func (b Backoff) maxDelay() time.Duration {
+	if b.Max == 0 {
+		return 30 * time.Second
+	}
+	return b.Max
+}
+
+// delay returns the time to wait before the given retry, starting with 0
+func (b Backoff) delay(retry int) time.Duration {
+	initial := b.Initial
+	if initial == 0 {
+		initial = 100 * time.Millisecond
+	}
+	maxDelay := b.maxDelay()
+	multiplier := b.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+
+	d := float64(initial) * math.Pow(multiplier, float64(retry))
+	if d > float64(maxDelay) {
+		d = float64(maxDelay)
+	}
+	if b.Jitter > 0 {
+		d += d * b.Jitter * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(d)
+}
+
+// RestartPolicy defines if and how often a service is restarted after Run returned.
+// When a failing service is not restarted anymore, all services in the container are stopped.
+type RestartPolicy struct {
+	Mode    RestartMode
+	Backoff Backoff
+	// MaxRestarts limits the number of restarts within Window, 0 allows unlimited restarts
+	MaxRestarts int
+	// Window in which restarts are counted against MaxRestarts, 0 counts all restarts
+	Window time.Duration
+	// ResetAfter is the time Run must last until the backoff starts again with the initial delay, defaults to Backoff.Max
+	ResetAfter time.Duration
+}
+
+// resetAfter returns the time Run must last until the backoff is reset
+func (p RestartPolicy) resetAfter() time.Duration {
+	if p.ResetAfter == 0 {
+		return p.Backoff.maxDelay()
+	}
+	return p.ResetAfter
+}
+
+// nextRestart records a restart of the service and returns the delay before the restart.
+// It returns false when the service must not be restarted.
+func (c *Container) nextRestart(rc *runContext, runErr error) (time.Duration, bool) {
+	policy := rc.service.restart
+	switch policy.Mode {
+	case RestartOnFailure:
+		if runErr == nil {
+			return 0, false
+		}
+	case RestartAlways:
+	default:
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if rc.state == StateStopping || rc.stopped() {
+		// The service is stopped on purpose
+		return 0, false
+	}
+
+	now := time.Now()
+	if now.Sub(rc.since[StateRunning]) >= policy.resetAfter() {
+		// The service ran long enough, start over with the initial delay
+		rc.backoffRetry = 0
+	}
+	counted := rc.countedRestarts
+	if policy.Window > 0 {
+		recent := rc.restartTimes[:0]
+		for _, t := range rc.restartTimes {
+			if now.Sub(t) < policy.Window {
+				recent = append(recent, t)
+			}
+		}
+		rc.restartTimes = recent
+		counted = len(recent)
+	}
+	if policy.MaxRestarts > 0 && counted >= policy.MaxRestarts {
+		c.logger().Error("Service exceeded max restarts", "name", rc.service.name, "maxRestarts", policy.MaxRestarts, "window", policy.Window)
+		return 0, false
+	}
+
+	delay := policy.Backoff.delay(rc.backoffRetry)
+	if policy.Window > 0 {
+		rc.restartTimes = append(rc.restartTimes, now)
+	}
+	rc.countedRestarts++
+	rc.backoffRetry++
+	rc.restarts++
+	c.setState(rc, StateRestarting)
+	c.logger().Info("Restarting service", "name", rc.service.name, "delay", delay, "restarts", rc.restarts)
+	return delay, true
+}
+
+// RestartCounts returns how often each service was restarted
+func (c *Container) RestartCounts() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := map[string]int{}
+	for name, rc := range c.runContexts {
+		counts[name] = rc.restarts
+	}
+	return counts
+}
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fastBackoff = service.Backoff{Initial: time.Millisecond, Max: 5 * time.Millisecond}
+
+func TestRestartOnFailure(t *testing.T) {
+	c := service.NewContainer()
+	runErr := errors.New("connection lost")
+	var runs atomic.Int32
+
+	service.New("consumer").
+		Restart(service.RestartPolicy{Mode: service.RestartOnFailure, Backoff: fastBackoff}).
+		Run(func(ctx context.Context) error {
+			if runs.Add(1) < 3 {
+				return runErr
+			}
+			<-ctx.Done()
+			return nil
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return runs.Load() == 3
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 1, c.RunningCount())
+
+	c.StopAll()
+	c.WaitAllStopped()
+	assert.Equal(t, map[string]int{"consumer": 2}, c.RestartCounts())
+	assert.ErrorIs(t, c.ServiceErrors()["consumer"], runErr)
+}
+
+func TestRestartExceedsMaxRestarts(t *testing.T) {
+	c := service.NewContainer()
+	runErr := errors.New("connection lost")
+	var runs atomic.Int32
+
+	service.New("consumer").
+		Restart(service.RestartPolicy{
+			Mode:        service.RestartOnFailure,
+			Backoff:     fastBackoff,
+			MaxRestarts: 3,
+			Window:      time.Minute,
+		}).
+		Run(func(ctx context.Context) error {
+			runs.Add(1)
+			return runErr
+		}).
+		Register(c)
+	s1 := &testService{Name: "s1"}
+	c.Register(s1)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+
+	// After exceeding the max restarts all services are stopped
+	c.WaitAllStopped()
+	assert.Equal(t, int32(4), runs.Load())
+	assert.Equal(t, 3, c.RestartCounts()["consumer"])
+	assertServiceStartedAndStopped(t, s1)
+}
+
+func TestRestartAlways(t *testing.T) {
+	c := service.NewContainer()
+	var runs atomic.Int32
+
+	service.New("worker").
+		Restart(service.RestartPolicy{Mode: service.RestartAlways, Backoff: fastBackoff}).
+		Run(func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return runs.Load() > 3
+	}, time.Second, time.Millisecond)
+
+	c.StopAll()
+	c.WaitAllStopped()
+	assert.Len(t, c.ServiceErrors(), 0)
+}
+
+// The backoff starts over when Run lasted longer than ResetAfter
+func TestRestartResetsBackoff(t *testing.T) {
+	c := service.NewContainer()
+	var runs atomic.Int32
+
+	service.New("worker").
+		Restart(service.RestartPolicy{
+			Mode:       service.RestartAlways,
+			Backoff:    service.Backoff{Initial: time.Millisecond, Multiplier: 1000, Max: time.Minute},
+			ResetAfter: 5 * time.Millisecond,
+		}).
+		Run(func(ctx context.Context) error {
+			runs.Add(1)
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return runs.Load() > 3
+	}, time.Second, time.Millisecond)
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
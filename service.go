@@ -8,23 +8,36 @@
 // All services have to implement the Runner interface. Run() is blocking and only returns when the service stops working.
 //
 // All services inside one container are started and stopped together. If one service fails, all are stopped.
+// Other strategies are available via Container.SetStrategy(). Containers can be nested to build supervision trees.
+// Services can depend on each other, see DependsOn(). Dependencies are started before and stopped after their dependents.
 package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type RunFunc func(ctx context.Context) error
 type InitFunc func(ctx context.Context) error
+type StopFunc func(ctx context.Context) error
+type ReadyFunc func(ctx context.Context) error
+type HealthFunc func(ctx context.Context) error
+type CloseFunc func(ctx context.Context) error
 
 type genericService struct {
-	name string
-	init InitFunc
-	run  RunFunc
+	name   string
+	init   InitFunc
+	run    RunFunc
+	stop   StopFunc
+	ready  ReadyFunc
+	health HealthFunc
+	close  CloseFunc
 }
 
 func (sr *genericService) Init(ctx context.Context) error {
@@ -37,205 +50,671 @@ func (sr *genericService) Run(ctx context.Context) error {
 	return sr.run(ctx)
 }
 
+func (sr *genericService) Stop(ctx context.Context) error {
+	if sr.stop == nil {
+		return nil
+	}
+	return sr.stop(ctx)
+}
+
+func (sr *genericService) Ready(ctx context.Context) error {
+	if sr.ready == nil {
+		return nil
+	}
+	return sr.ready(ctx)
+}
+
+func (sr *genericService) Health(ctx context.Context) error {
+	if sr.health == nil {
+		return nil
+	}
+	return sr.health(ctx)
+}
+
+func (sr *genericService) Close(ctx context.Context) error {
+	if sr.close == nil {
+		return nil
+	}
+	return sr.close(ctx)
+}
+
+func (sr *genericService) hasReady() bool {
+	return sr.ready != nil
+}
+
 func (sr *genericService) String() string {
 	return sr.name
 }
 
 type runContext struct {
 	service *serviceInfo
-	running bool
-	done    chan error
+	// Context passed to Run, canceled when the service should stop
+	ctx    context.Context
+	cancel context.CancelFunc
+	// done is closed when the service stopped or will never run
+	done chan struct{}
+	// released is closed when the service stopped or exceeded its stop timeout during StopAll
+	released chan struct{}
+	// ready is closed when the service is ready or failed to get ready, readyErr is set before
+	ready    chan struct{}
+	readyErr error
+
+	// All fields below are guarded by Container.mu
+	state State
+	// Time each state was entered the last time
+	since        map[State]time.Time
+	initDuration time.Duration
+	initErr      error
+	// started is set when Run is scheduled, from then on done is closed by the run go-routine
+	started bool
+	// stopRequested is set before Stop is called, errors of Run are not treated as failure from then on
+	stopRequested bool
+	// Result of the last health check
+	health ServiceHealth
+	// Last error returned by Run, also kept when a restarted service stops without error
 	err     error
+	stopErr error
+	// Error returned by Close
+	closeErr error
+	// Time the last stop took, from calling Stop until Run returned
+	stopDuration time.Duration
+	// Number of errors returned by Init, Run, Stop and Close
+	failures int
+	// Number of restarts, see RestartPolicy
+	restarts int
+	// Restarts counted against RestartPolicy.MaxRestarts, the times are only kept when there is a window
+	countedRestarts int
+	restartTimes    []time.Time
+	// backoffRetry is the number of restarts since Run lasted longer than RestartPolicy.ResetAfter
+	backoffRetry int
 }
 
 type serviceInfo struct {
-	name    string
-	service Runner
+	name        string
+	service     Runner
+	dependsOn   []string
+	stopTimeout time.Duration
+	initTimeout time.Duration
+	initRetry   InitRetryPolicy
+	restart     RestartPolicy
+	// Failures of optional services do not affect other services
+	optional     bool
+	registeredAt time.Time
 }
 
 func (rc *runContext) wait() {
-	if !rc.running {
-		return
-	}
 	<-rc.done
 }
 
+// isDone reports if the service stopped or will never run
+func (rc *runContext) isDone() bool {
+	select {
+	case <-rc.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stopped reports if the service is stopped on purpose, must be called with Container.mu held
+func (rc *runContext) stopped() bool {
+	return rc.stopRequested || rc.ctx.Err() != nil
+}
+
+// isRunning reports if Run of the service is executing, must be called with Container.mu held
+func (rc *runContext) isRunning() bool {
+	return rc.state == StateRunning || rc.state == StateStopping
+}
+
 // Container with all services
 // The Container handles the following lifecycle:
 // - Register all services
 // - Start all services
 // - Stop all services
-// If a single service fails during init or run, all services inside the container are stopped.
+// If a single service fails during init or run, all services inside the container are stopped, see SetStrategy().
+// All methods are safe to be called from multiple go-routines.
 type Container struct {
+	log atomic.Pointer[slog.Logger]
+	// Guards all fields below
+	mu   sync.Mutex
+	name string
 	// Context in which all services are running
 	runCtx context.Context
 	// Cancel method of the runCtx, when called all services should stop
-	runCtxCancel      context.CancelFunc
-	services          []*serviceInfo
-	runContexts       map[string]*runContext
-	log               *slog.Logger
-	callOnStopAllOnce sync.Once
+	runCtxCancel context.CancelFunc
+	services     []*serviceInfo
+	// All services in order of their dependencies, nil until StartAll was called
+	order       []*serviceInfo
+	runContexts map[string]*runContext
+	// Names of all successfully initialized services in order of their initialization
+	initOrder []string
+	// Closed after all services are stopped and closed, nil until StartAll was called
+	closed chan struct{}
+	// Number of running Add, StartService and RestartService calls, see beginChange
+	changes int
+	// Closed and set to nil when a change is done, created by waitServices
+	changed           chan struct{}
+	initConcurrency   int
+	stopTimeout       time.Duration
+	closeTimeout      time.Duration
+	gracePeriod       time.Duration
+	readyTimeout      time.Duration
+	healthInterval    time.Duration
+	strategy          Strategy
+	repanic           bool
+	captureStacks     bool
+	tracer            Tracer
+	observers         []LifecycleObserver
+	callOnStopAllOnce *sync.Once
 	shutdownCallbacks []func()
+
+	// Has its own lock to not block the container while events are delivered
+	events broker
 }
 
 func NewContainer() *Container {
-	nopLogger := slog.New(NopHandler{})
-	return &Container{
+	c := &Container{
 		services:    make([]*serviceInfo, 0),
 		runContexts: map[string]*runContext{},
-		log:         nopLogger,
 	}
+	c.log.Store(slog.New(NopHandler{}))
+	return c
 }
 
-var defaultContainer *Container
+var (
+	defaultContainer     *Container
+	defaultContainerOnce sync.Once
+)
 
 func Default() *Container {
-	if defaultContainer == nil {
+	defaultContainerOnce.Do(func() {
 		defaultContainer = NewContainer()
-	}
+	})
 	return defaultContainer
 }
 
 func (c *Container) SetLogger(logger *slog.Logger) {
-	c.log = logger
+	c.log.Store(logger)
+}
+
+func (c *Container) logger() *slog.Logger {
+	return c.log.Load()
+}
+
+// loggerKey is the context key of the container logger passed to Run
+type loggerKey struct{}
+
+// contextLogger returns the logger of the container that runs the service, or a nop logger outside a container
+func contextLogger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.New(NopHandler{})
+}
+
+// SetInitConcurrency enables parallel initialization of up to n services at once.
+// Services still wait for the Init of all their dependencies to succeed.
+// A value of 0 or 1 initializes all services sequentially, which is the default.
+func (c *Container) SetInitConcurrency(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.initConcurrency = n
+}
+
+// SetReadyTimeout makes StartAll block until all services are ready, see Readier.
+// If not all services are ready within the timeout, StartAll stops all services and returns an error.
+// A value of 0 lets StartAll return right after all services are started, which is the default.
+func (c *Container) SetReadyTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readyTimeout = timeout
+}
+
+// SetStopTimeout sets the default time each service gets to stop gracefully, see StopTimeout() to set it per service.
+// A value of 0 waits forever, which is the default.
+func (c *Container) SetStopTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopTimeout = timeout
 }
 
 // Register adds a service to the list of services to be initialized
-func (c *Container) Register(service Runner) {
+func (c *Container) Register(service Runner, opts ...Option) {
+	s := newServiceInfo(service, opts...)
+	c.mu.Lock()
+	if c.lookup(s.name) != nil {
+		c.mu.Unlock()
+		panic(fmt.Sprintf("Service '%s' already registered", s.name))
+	}
+	c.services = append(c.services, s)
+	c.mu.Unlock()
+	c.logger().Info("Registered service", "name", s.name)
+	c.onRegistered(s)
+}
+
+func newServiceInfo(service Runner, opts ...Option) *serviceInfo {
 	name := fmt.Sprintf("%T", service)
 	if s, ok := service.(fmt.Stringer); ok {
 		name = s.String()
 	}
 
+	s := &serviceInfo{
+		name:         name,
+		service:      service,
+		registeredAt: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// lookup returns the registered service with the given name or nil, must be called with c.mu held
+func (c *Container) lookup(name string) *serviceInfo {
 	for _, s := range c.services {
 		if s.name == name {
-			panic(fmt.Sprintf("Service '%s' already registered", name))
+			return s
 		}
 	}
-
-	c.services = append(c.services, &serviceInfo{
-		name:    name,
-		service: service,
-	})
-	c.log.Info("Registered service", "name", name)
+	return nil
 }
 
-func newRunContext(s *serviceInfo) *runContext {
+func newRunContext(ctx context.Context, s *serviceInfo, state State) *runContext {
+	// The service context is not canceled together with its parent,
+	// the container cancels it when all dependent services are stopped.
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
 	return &runContext{
-		service: s,
-		done:    make(chan error, 1),
+		service:  s,
+		ctx:      runCtx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		released: make(chan struct{}),
+		ready:    make(chan struct{}),
+		state:    state,
+		since:    map[State]time.Time{state: time.Now()},
 	}
 }
 
 func (c *Container) initOne(ctx context.Context, s *serviceInfo) error {
-	c.onInit(s)
-	runner := newRunContext(s)
+	c.mu.Lock()
 	if _, ok := c.runContexts[s.name]; ok {
+		c.mu.Unlock()
 		return fmt.Errorf("service '%s' already started", s.name)
 	}
-
+	if err := c.runCtx.Err(); err != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("service '%s' not started, container is stopping: %w", s.name, err)
+	}
+	runner := newRunContext(c.runCtx, s, StateInitializing)
 	c.runContexts[s.name] = runner
+	c.mu.Unlock()
 
 	// Execute initialization code if any
+	c.onInit(s)
+	var err error
+	start := time.Now()
 	if initer, ok := s.service.(Initer); ok {
-		c.log.Info("Initializing service", "name", s.name)
-		err := initer.Init(ctx)
-		if err != nil {
-			go func() {
-				// Let the runner stop immediately
-				// The error is nil, since it is the "Run()" error
-				runner.done <- nil
-			}()
-			c.log.Debug("Failed to initialize service", "name", s.name, "error", err)
-			return fmt.Errorf("failed to init service %s: %w", s.name, err)
-		}
-		c.log.Info("Initialized service", "name", s.name)
+		initCtx, span := c.startSpan(ctx, SpanInit, s.name)
+		err = c.callInit(initCtx, s, initer)
+		span.End(err)
+	}
+	duration := time.Since(start)
+	c.onInitDone(s, duration, err)
+	if err != nil {
+		c.logger().Debug("Failed to initialize service", "name", s.name, "error", err)
+		err = &ServiceError{Service: s.name, Phase: PhaseInit, Err: err}
+	} else if _, ok := s.service.(Initer); ok {
+		c.logger().Info("Initialized service", "name", s.name)
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	runner.initDuration = duration
+	if err != nil {
+		runner.initErr = err
+		c.setState(runner, StateFailed)
+		// The service will never run, StopAll must not wait for it
+		runner.started = true
+		close(runner.done)
+		return err
+	}
+	c.setState(runner, StateInitialized)
+	c.initOrder = append(c.initOrder, s.name)
 	return nil
 }
 
-func (c *Container) runOne(ctx context.Context, s *serviceInfo) error {
-	c.onRun(s)
+func (c *Container) runOne(s *serviceInfo) error {
+	c.mu.Lock()
 	runner, ok := c.runContexts[s.name]
 	if !ok {
+		c.mu.Unlock()
 		return fmt.Errorf("service '%s' not initialized", s.name)
 	}
-	if runner.running {
+	if runner.started {
+		c.mu.Unlock()
 		return fmt.Errorf("service '%s' already running", s.name)
 	}
+	runner.started = true
+	var deps []*runContext
+	for _, name := range s.dependsOn {
+		deps = append(deps, c.runContexts[name])
+	}
+	c.mu.Unlock()
 
-	// Execute the actual run method in background
-	runner.running = true
+	if allReady(deps) {
+		c.launch(runner)
+		return nil
+	}
+
+	// Wait in background for all dependencies to be ready
 	go func() {
-		logger := c.log.With("name", s.name)
-		logger.Info("Starting service")
-		runErr := s.service.Run(ctx)
+		start := time.Now()
+		for _, dep := range deps {
+			select {
+			case <-dep.ready:
+				if dep.readyErr == nil {
+					continue
+				}
+			case <-dep.done:
+			case <-runner.ctx.Done():
+			}
+			// A service that can not start because of its dependency failed, unless it is stopped on purpose
+			c.mu.Lock()
+			var err error
+			if !runner.stopped() {
+				err = fmt.Errorf("not started, dependency %s is not ready", dep.service.name)
+				runner.err = err
+				c.setState(runner, StateFailed)
+			} else {
+				c.setState(runner, StateStopped)
+			}
+			c.mu.Unlock()
+			close(runner.done)
+			if err != nil {
+				c.logger().Warn("Service not started, dependency is not ready", "name", s.name, "dependency", dep.service.name)
+				c.fail(runner, time.Since(start), err)
+			}
+			return
+		}
+		c.launch(runner)
+	}()
+
+	return nil
+}
+
+// launch executes the Run method of the service in background.
+// The service is restarted according to its RestartPolicy.
+func (c *Container) launch(runner *runContext) {
+	s := runner.service
+	c.mu.Lock()
+	c.setState(runner, StateRunning)
+	c.mu.Unlock()
+	go func() {
+		logger := c.logger().With("name", s.name)
+		var runErr error
+		for {
+			logger.Info("Starting service")
+			c.onRun(s)
+			start := time.Now()
+			runCtx, span := c.startSpan(runner.ctx, SpanRun, s.name)
+			runCtx = context.WithValue(runCtx, loggerKey{}, c.logger())
+			runErr = c.safeCall(s, func() error {
+				return runLabeled(runCtx, s)
+			})
+			span.End(runErr)
+			c.onStopped(s, time.Since(start), runErr)
+			c.mu.Lock()
+			deliberate := runner.stopped()
+			c.mu.Unlock()
+			if runErr != nil && !deliberate {
+				c.onFailed(LifecycleEvent{Service: s.name, Phase: PhaseRun, Duration: time.Since(start), Err: runErr})
+			}
+			if runErr != nil {
+				logger.Error("Service stopped with error", "error", runErr)
+				c.mu.Lock()
+				runner.err = runErr
+				c.mu.Unlock()
+			} else {
+				logger.Info("Service stopped")
+			}
+
+			delay, ok := c.nextRestart(runner, runErr)
+			if !ok {
+				break
+			}
+			c.publish(Event{Type: EventRestartScheduled, Service: s.name, Phase: PhaseRun, Duration: delay, Err: runErr})
+			rest := c.stopRest(runner)
+			select {
+			case <-time.After(delay):
+			case <-runner.ctx.Done():
+			}
+			c.mu.Lock()
+			restart := runner.state == StateRestarting && !runner.stopped()
+			if restart {
+				c.setState(runner, StateRunning)
+			}
+			c.mu.Unlock()
+			if !restart {
+				runErr = nil
+				break
+			}
+			c.startRest(rest)
+		}
+
+		c.mu.Lock()
 		if runErr != nil {
-			logger.Error("Service stopped with error", "error", runErr)
+			c.setState(runner, StateFailed)
 		} else {
-			logger.Info("Service stopped")
+			c.setState(runner, StateStopped)
 		}
-		runner.err = runErr
-		runner.running = false
+		deliberate := runner.stopped()
+		c.mu.Unlock()
+		// Release Ready and everything else that waits for the context of the service
+		runner.cancel()
 		close(runner.done)
-		if runErr != nil {
-			c.StopAll()
+
+		// Errors of services that are stopped on purpose do not affect other services
+		if runErr != nil && !deliberate {
+			c.handleFailure(runner, runErr)
 		}
 	}()
-
-	return nil
+	if isReadier(s.service) {
+		go c.awaitReady(runner)
+	} else {
+		// Services without Readier are ready right away
+		close(runner.ready)
+	}
 }
 
 // StartAll starts all services inside the container
-// the function does not block, services are started in background
+// the function does not block, services are started in background.
+// See SetReadyTimeout() to wait until all services are ready.
 func (c *Container) StartAll(ctx context.Context) error {
+	ctx, span := c.startSpan(ctx, SpanStartAll, "")
+	err := c.startAll(ctx)
+	span.End(err)
+	return err
+}
+
+func (c *Container) startAll(ctx context.Context) error {
+	c.mu.Lock()
 	if c.runCtx != nil {
+		c.mu.Unlock()
 		panic("Container.StartAll can only be called once")
 	}
-	c.runCtx, c.runCtxCancel = context.WithCancel(ctx)
+	runCtx, cancel := context.WithCancel(ctx)
+	c.runCtx, c.runCtxCancel = runCtx, cancel
+	c.callOnStopAllOnce = &sync.Once{}
+	c.closed = make(chan struct{})
+	order, err := sortServices(c.services)
+	c.order = order
+	healthInterval, readyTimeout := c.healthInterval, c.readyTimeout
+	c.mu.Unlock()
+
+	// Services are stopped in reverse dependency order, no matter if StopAll was called or the parent context is done
+	context.AfterFunc(runCtx, c.stopInOrder)
+	if err != nil {
+		return c.abortStart(err)
+	}
 
-	// Iterate over all services to initialize them
-	for i := range c.services {
-		s := c.services[i]
-		// TODO: Should we allow services to optionally initialize in parallel? Then we might get multiple errors returned
-		err := c.initOne(c.runCtx, s)
-		if err != nil {
-			c.StopAll()
-			return err
-		}
+	if err := c.initAll(runCtx, order); err != nil {
+		return c.abortStart(err)
+	}
+	if err := runCtx.Err(); err != nil {
+		return c.abortStart(fmt.Errorf("container stopped during init: %w", err))
 	}
 
 	// Iterate over all services to run them
-	for i := range c.services {
-		s := c.services[i]
-		err := c.runOne(c.runCtx, s)
+	for _, s := range order {
+		if !c.initialized(s) {
+			// Optional services that failed to initialize, see initAll
+			continue
+		}
+		err := c.runOne(s)
 		if err != nil {
-			c.StopAll()
-			return err
+			return c.abortStart(err)
+		}
+	}
+
+	if healthInterval != 0 {
+		go c.pollHealth(runCtx, healthInterval)
+	}
+
+	if readyTimeout != 0 {
+		if err := c.waitReady(runCtx, readyTimeout); err != nil {
+			return c.abortStart(err)
 		}
 	}
 
 	return nil
 }
 
+// abortStart stops all services when StartAll fails.
+// Services that were not started yet are marked as done, since they will never run.
+func (c *Container) abortStart(err error) error {
+	c.mu.Lock()
+	for _, rc := range c.runContexts {
+		if !rc.started {
+			rc.started = true
+			if rc.state == StateInitialized {
+				c.setState(rc, StateStopped)
+			}
+			close(rc.done)
+		}
+	}
+	c.mu.Unlock()
+	c.StopAll()
+	return err
+}
+
+// stopInOrder stops each service as soon as all services depending on it are stopped.
+// After all services are stopped, they are closed.
+func (c *Container) stopInOrder() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	wg := sync.WaitGroup{}
+	wg.Add(len(c.runContexts))
+	closed, runCtx := c.closed, c.runCtx
+	go func() {
+		_, span := c.startSpan(context.WithoutCancel(runCtx), SpanStopAll, "")
+		wg.Wait()
+		c.closeAll()
+		span.End(c.shutdownErrors())
+		close(closed)
+	}()
+	deps := dependents(c.order)
+	for name, rc := range c.runContexts {
+		var waitFor []*runContext
+		for _, dep := range deps[name] {
+			if d, ok := c.runContexts[dep]; ok {
+				waitFor = append(waitFor, d)
+			}
+		}
+		go func() {
+			for _, d := range waitFor {
+				<-d.released
+			}
+			c.stopOne(context.Background(), rc)
+			close(rc.released)
+			wg.Done()
+		}()
+	}
+}
+
+// stopOne calls the optional Stop method of a running service and cancels its context.
+// It returns when the service stopped or its stop timeout is exceeded.
+func (c *Container) stopOne(ctx context.Context, rc *runContext) {
+	c.mu.Lock()
+	timeout := c.stopTimeout
+	if rc.service.stopTimeout != 0 {
+		timeout = rc.service.stopTimeout
+	}
+	running := rc.state == StateRunning
+	stopping := running || rc.state == StateRestarting
+	// Stop might let Run return an error before the context is canceled
+	rc.stopRequested = true
+	if stopping {
+		c.setState(rc, StateStopping)
+	}
+	c.mu.Unlock()
+	start := time.Now()
+
+	ctx, cancel := context.WithCancel(ctx)
+	if timeout != 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	if stopper, ok := rc.service.service.(Stopper); ok && running {
+		c.logger().Info("Stopping service", "name", rc.service.name)
+		err := c.safeCall(rc.service, func() error {
+			return stopper.Stop(ctx)
+		})
+		if err != nil {
+			c.logger().Error("Failed to stop service", "name", rc.service.name, "error", err)
+			c.onFailed(LifecycleEvent{Service: rc.service.name, Phase: PhaseStop, Err: err})
+			c.mu.Lock()
+			rc.stopErr = &ServiceError{Service: rc.service.name, Phase: PhaseStop, Err: err}
+			c.mu.Unlock()
+		}
+	}
+	rc.cancel()
+
+	select {
+	case <-rc.done:
+		if stopping {
+			c.mu.Lock()
+			rc.stopDuration = time.Since(start)
+			c.mu.Unlock()
+		}
+	case <-ctx.Done():
+		c.logger().Warn("Service did not stop within timeout", "name", rc.service.name, "timeout", timeout)
+	}
+}
+
 // StopAll gracefully stops all services.
 // If you need a timeout, passe a context with Timeout or Deadline
 func (c *Container) StopAll() {
-	c.callOnStopAllOnce.Do(func() {
-		c.onStopAll()
-	})
-	if c.runCtxCancel == nil {
+	c.mu.Lock()
+	cancel, once := c.runCtxCancel, c.callOnStopAllOnce
+	c.mu.Unlock()
+	if cancel == nil {
 		panic("call Container.StartAll() before StopAll()")
 	}
-	c.runCtxCancel()
+	once.Do(func() {
+		c.publish(Event{Type: EventShutdown})
+		c.onStopAll()
+	})
+	cancel()
 }
 
 func (c *Container) runningServices() []*runContext {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	rcs := make([]*runContext, 0)
 	for i := range c.runContexts {
 		rc := c.runContexts[i]
-		if rc.running {
+		if rc.isRunning() {
 			rcs = append(rcs, rc)
 		}
 	}
@@ -243,20 +722,19 @@ func (c *Container) runningServices() []*runContext {
 }
 
 func (c *Container) RunningCount() int {
-	cnt := 0
-	for _, rc := range c.runContexts {
-		if rc.running {
-			cnt++
-		}
-	}
-	return cnt
+	return len(c.runningServices())
 }
 
+// ServiceNames returns the names of all started services in order of registration
 func (c *Container) ServiceNames() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	var names []string
 
-	for _, rc := range c.runContexts {
-		names = append(names, rc.service.name)
+	for _, s := range c.services {
+		if _, ok := c.runContexts[s.name]; ok {
+			names = append(names, s.name)
+		}
 	}
 
 	return names
@@ -268,47 +746,94 @@ func (c *Container) WaitAllStopped() {
 
 // WaitAllStoppedTimeout blocks until all services are stopped or timeout is exceeded
 // calling with timout of 0 will wait forever - better use WaitAllStopped() then.
-// After the timeout is reached, services might still run. Call Container.StopAll() to stop them.
-func (c *Container) WaitAllStoppedTimeout(timeout time.Duration) {
-	if c.runCtxCancel == nil {
-		panic("call Container.StartAll() before WaitAllStopped()")
-	}
-
-	var ctx context.Context
-	var cancel context.CancelFunc
-
+// After the timeout is reached, services might still run and their names are returned. Call Container.StopAll() to stop them.
+func (c *Container) WaitAllStoppedTimeout(timeout time.Duration) []string {
+	ctx := context.Background()
 	if timeout != 0 {
-		ctx, cancel = context.WithTimeout(context.Background(), timeout)
-
-	} else {
-		ctx, cancel = context.WithCancel(context.Background())
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
-	wg := sync.WaitGroup{}
-	wg.Add(len(c.runContexts))
-	for k := range c.runContexts {
-		rc := c.runContexts[k]
-		go func() {
-			rc.wait()
-			c.onStopped(rc)
-			wg.Done()
-		}()
+	var stillRunning *StillRunningError
+	if errors.As(c.Wait(ctx), &stillRunning) {
+		return stillRunning.Services
+	}
+	return nil
+}
+
+// Wait blocks until all services are stopped or ctx is done.
+// When the container is stopping, Wait also waits until all services are closed, see Closer.
+// It returns the errors of all failed services joined like Run.
+// When ctx is done first, a *StillRunningError with the services that did not stop is returned.
+// After ctx is done, services might still run. Call Container.StopAll() to stop them.
+func (c *Container) Wait(ctx context.Context) error {
+	c.mu.Lock()
+	started := c.runCtxCancel != nil
+	runCtx, closed := c.runCtx, c.closed
+	c.mu.Unlock()
+	if !started {
+		panic("call Container.StartAll() before Wait()")
 	}
 
-	// wait till all services are stopped
+	stopped := make(chan struct{})
 	go func() {
-		wg.Wait()
-		cancel()
+		c.waitServices()
+		if runCtx.Err() != nil {
+			// The container is stopping, wait for all services to be closed
+			<-closed
+		}
+		close(stopped)
 	}()
 
-	<-ctx.Done()
+	select {
+	case <-stopped:
+		return c.runErrors()
+	case <-ctx.Done():
+		if err := c.stillRunning(ctx.Err()); len(err.Services) > 0 {
+			return err
+		}
+		return c.runErrors()
+	}
+}
+
+// waitServices blocks until all services are done, including services that are started while waiting
+func (c *Container) waitServices() {
+	for {
+		c.mu.Lock()
+		var running *runContext
+		for _, rc := range c.runContexts {
+			if !rc.isDone() {
+				running = rc
+				break
+			}
+		}
+		if running == nil && c.changes == 0 {
+			c.mu.Unlock()
+			return
+		}
+		if c.changed == nil {
+			c.changed = make(chan struct{})
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		if running != nil {
+			running.wait()
+		} else {
+			<-changed
+		}
+	}
 }
 
 // ServiceErrors returns all errors occurred in services
+// Errors returned by Init, Run, Stop and Close of the same service are joined
 func (c *Container) ServiceErrors() map[string]error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	errs := map[string]error{}
 	for _, rc := range c.runContexts {
-		if rc.err != nil {
-			errs[rc.service.name] = rc.err
+		if err := errors.Join(rc.initErr, rc.err, rc.stopErr, rc.closeErr); err != nil {
+			errs[rc.service.name] = err
 		}
 	}
 	return errs
@@ -317,28 +842,18 @@ func (c *Container) ServiceErrors() map[string]error {
 // onStopAll is called when all services get stopped
 // This method is only called once per container
 func (c *Container) onStopAll() {
-	for _, f := range c.shutdownCallbacks {
+	c.mu.Lock()
+	callbacks := slices.Clone(c.shutdownCallbacks)
+	c.mu.Unlock()
+	for _, f := range callbacks {
 		f()
 	}
 }
 
-// onInit is called before a service Init method is called
-func (c *Container) onInit(s *serviceInfo) {
-
-}
-
-// onRun is called before a service Run method is called
-func (c *Container) onRun(s *serviceInfo) {
-
-}
-
-// onStopped is called after a service was stopped
-func (c *Container) onStopped(rc *runContext) {
-
-}
-
 // OnShutdown is called when the container is stopped and all services are going to be stopped
 // The callback is only called once per container
 func (c *Container) OnShutdown(f func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.shutdownCallbacks = append(c.shutdownCallbacks, f)
 }
@@ -7,13 +7,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"log/slog"
+	"sync"
 	"testing"
 	"time"
 )
 
 var _ service.Initer = &testService{}
 var _ service.Runner = &testService{}
-var _ fmt.Stringer = testService{}
+var _ fmt.Stringer = &testService{}
 
 // testService is a service that tracks it's state to be checked in tests
 type testService struct {
@@ -26,19 +27,23 @@ type testService struct {
 	ErrorAfterRun error
 	// If set the service will not wait for <-ctx.Done()
 	SkipWaitForCtx bool
-	initialized    bool
-	started        bool
-	running        bool
-	stopped        bool
-	err            error
-	startedCh      chan struct{}
+	// Guards the state below, which is written by the service and checked by the tests
+	mu          sync.Mutex
+	initialized bool
+	started     bool
+	running     bool
+	stopped     bool
+	err         error
+	startedCh   chan struct{}
 }
 
-func (t testService) String() string {
+func (t *testService) String() string {
 	return fmt.Sprintf("testService.%s", t.Name)
 }
 
 func (t *testService) Init(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	if t.initialized {
 		return fmt.Errorf("service %s was already initialized", t.Name)
 	}
@@ -51,11 +56,14 @@ func (t *testService) Init(ctx context.Context) error {
 }
 
 func (t *testService) Run(ctx context.Context) error {
+	t.mu.Lock()
 	if t.running {
+		t.mu.Unlock()
 		return fmt.Errorf("service %s already running", t.Name)
 	}
 	t.running = true
 	if t.started {
+		t.mu.Unlock()
 		return fmt.Errorf("service %s was already started", t.Name)
 	}
 	t.started = true
@@ -66,12 +74,17 @@ func (t *testService) Run(ctx context.Context) error {
 	if t.ErrorDuringRun != nil {
 		t.running = false
 		t.stopped = true
+		t.mu.Unlock()
 		return t.ErrorDuringRun
 	}
+	t.mu.Unlock()
 
 	if !t.SkipWaitForCtx {
 		<-ctx.Done()
 	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.running = false
 
 	if t.stopped {
@@ -84,6 +97,8 @@ func (t *testService) Run(ctx context.Context) error {
 
 func assertServiceStartedAndStopped(t *testing.T, s *testService) {
 	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	assert.True(t, s.initialized, "initialized")
 	assert.True(t, s.started, "started")
 	assert.True(t, s.stopped, "stopped")
@@ -93,6 +108,8 @@ func assertServiceStartedAndStopped(t *testing.T, s *testService) {
 
 func assertServiceStillRunning(t *testing.T, s *testService) {
 	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	assert.True(t, s.initialized)
 	assert.True(t, s.started)
 	assert.False(t, s.stopped, "Stopped")
@@ -102,6 +119,8 @@ func assertServiceStillRunning(t *testing.T, s *testService) {
 
 func assertServiceOnlyInitialized(t *testing.T, s *testService) {
 	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	assert.True(t, s.initialized)
 	assert.False(t, s.started)
 	assert.False(t, s.stopped)
@@ -111,6 +130,8 @@ func assertServiceOnlyInitialized(t *testing.T, s *testService) {
 
 func assertServiceNeverStarted(t *testing.T, s *testService) {
 	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	assert.False(t, s.initialized)
 	assert.False(t, s.started)
 	assert.False(t, s.stopped)
@@ -240,7 +261,8 @@ func TestStopWhenInitFails(t *testing.T) {
 
 	// Expect all services to stop, since there was an error
 	c.WaitAllStopped()
-	assert.Len(t, c.ServiceErrors(), 0)
+	assert.Len(t, c.ServiceErrors(), 1)
+	assert.EqualError(t, c.ServiceErrors()["testService.s2"], "failed to init service testService.s2: service failed during init")
 	assertServiceOnlyInitialized(t, s1)
 	assertServiceNeverStarted(t, s2)
 	assertServiceNeverStarted(t, s3)
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+)
+
+// StillRunningError is returned when services did not stop in time
+type StillRunningError struct {
+	// Services that are still running in order of registration
+	Services []string
+	// Stacks of the goroutines started by each service, only set when enabled by SetCaptureStacks
+	Stacks map[string]string
+	Err    error
+}
+
+func (e *StillRunningError) Error() string {
+	return fmt.Sprintf("services still running: %s: %v", strings.Join(e.Services, ", "), e.Err)
+}
+
+func (e *StillRunningError) Unwrap() error {
+	return e.Err
+}
+
+// SetCaptureStacks enables capturing the goroutine stacks of services that did not stop in time.
+// The stacks are logged and returned as part of a StillRunningError.
+func (c *Container) SetCaptureStacks(capture bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.captureStacks = capture
+}
+
+// runLabeled calls Run of the service with a pprof label to attribute its goroutines to the service
+func runLabeled(ctx context.Context, s *serviceInfo) error {
+	var err error
+	pprof.Do(ctx, pprof.Labels("service", s.name), func(ctx context.Context) {
+		err = s.service.Run(ctx)
+	})
+	return err
+}
+
+// stillRunning logs all services that are not stopped yet and returns them as error
+func (c *Container) stillRunning(err error) *StillRunningError {
+	c.mu.Lock()
+	var names []string
+	for _, s := range c.services {
+		if rc, ok := c.runContexts[s.name]; ok && !rc.isDone() {
+			names = append(names, s.name)
+		}
+	}
+	capture := c.captureStacks
+	c.mu.Unlock()
+
+	e := &StillRunningError{Services: names, Err: err}
+	if len(names) == 0 {
+		return e
+	}
+	c.logger().Warn("Services did not stop in time", "services", names)
+	if capture {
+		e.Stacks = serviceStacks(names)
+		for _, name := range names {
+			c.logger().Warn("Goroutines of service still running", "name", name, "stacks", e.Stacks[name])
+		}
+	}
+	return e
+}
+
+// serviceStacks returns the stacks of all goroutines labeled with one of the service names
+func serviceStacks(names []string) map[string]string {
+	var buf bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&buf, 1)
+
+	stacks := map[string]string{}
+	for _, record := range strings.Split(buf.String(), "\n\n") {
+		for _, name := range names {
+			if strings.Contains(record, fmt.Sprintf(`"service":%q`, name)) {
+				stacks[name] += record + "\n\n"
+			}
+		}
+	}
+	return stacks
+}
@@ -0,0 +1,69 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func stuckInShutdown(stuck chan struct{}) error {
+	<-stuck
+	return nil
+}
+
+func TestStillRunning(t *testing.T) {
+	c := service.NewContainer()
+	c.SetCaptureStacks(true)
+	stuck := make(chan struct{})
+	defer close(stuck)
+	c.Register(blockingService("s1"))
+	service.New("stuck").
+		Run(func(ctx context.Context) error {
+			done := make(chan error)
+			go func() {
+				done <- stuckInShutdown(stuck)
+			}()
+			return <-done
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.StopAll()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = c.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	var stillRunning *service.StillRunningError
+	require.True(t, errors.As(err, &stillRunning))
+	assert.Equal(t, []string{"stuck"}, stillRunning.Services)
+	assert.EqualError(t, err, "services still running: stuck: context deadline exceeded")
+	// The stack of the goroutine started by the service is attributed to the service as well
+	assert.Contains(t, stillRunning.Stacks["stuck"], "stuckInShutdown")
+	assert.NotContains(t, stillRunning.Stacks, "s1")
+
+	assert.Equal(t, []string{"stuck"}, c.WaitAllStoppedTimeout(10*time.Millisecond))
+}
+
+func TestStillRunningWithoutStacks(t *testing.T) {
+	c := service.NewContainer()
+	c.Register(blockingService("s1"))
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var stillRunning *service.StillRunningError
+	require.True(t, errors.As(c.Wait(ctx), &stillRunning))
+	assert.Equal(t, []string{"s1"}, stillRunning.Services)
+	assert.Nil(t, stillRunning.Stacks)
+
+	c.StopAll()
+	assert.Nil(t, c.WaitAllStoppedTimeout(time.Second))
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ErrForcedShutdown is returned by RunUntilSignal when services did not stop gracefully
+var ErrForcedShutdown = errors.New("forced shutdown")
+
+// SetShutdownGracePeriod limits how long RunUntilSignal waits for services to stop after a signal was received.
+// A value of 0 waits forever, which is the default.
+func (c *Container) SetShutdownGracePeriod(period time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gracePeriod = period
+}
+
+// RunUntilSignal starts all services and stops them gracefully when one of the signals is received or ctx is done.
+// Without signals, SIGINT and SIGTERM are handled. A signal during StartAll cancels the start.
+// A second signal or an exceeded shutdown grace period returns immediately with ErrForcedShutdown.
+// It returns the errors of all failed services joined.
+func (c *Container) RunUntilSignal(ctx context.Context, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, signals...)
+	defer signal.Stop(sigCh)
+
+	// The first signal cancels the start, e.g. a retried Init or waiting for services to get ready
+	startCtx, cancelStart := context.WithCancel(ctx)
+	defer cancelStart()
+	startDone := make(chan struct{})
+	interrupted := make(chan os.Signal, 1)
+	go func() {
+		defer close(interrupted)
+		select {
+		case sig := <-sigCh:
+			c.logger().Info("Received signal, stopping services", "signal", sig)
+			interrupted <- sig
+			cancelStart()
+		case <-startDone:
+		}
+	}()
+	startErr := c.StartAll(startCtx)
+	close(startDone)
+	_, received := <-interrupted
+
+	// errs returns the errors of the failed start or of all services
+	errs := func() error {
+		if startErr != nil {
+			return errors.Join(startErr, c.shutdownErrors())
+		}
+		return c.runErrors()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		c.WaitAllStopped()
+		close(stopped)
+	}()
+
+	switch {
+	case received || startErr != nil:
+		c.StopAll()
+	default:
+		select {
+		case <-stopped:
+			// Services returned on their own, close them as well
+			c.StopAll()
+			c.WaitAllStopped()
+			return errs()
+		case sig := <-sigCh:
+			c.logger().Info("Received signal, stopping services", "signal", sig)
+			c.StopAll()
+		case <-ctx.Done():
+			c.logger().Info("Context done, stopping services", "error", ctx.Err())
+			c.StopAll()
+		}
+	}
+
+	c.mu.Lock()
+	gracePeriod := c.gracePeriod
+	c.mu.Unlock()
+	var timeout <-chan time.Time
+	if gracePeriod > 0 {
+		timer := time.NewTimer(gracePeriod)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-stopped:
+		return errs()
+	case sig := <-sigCh:
+		c.logger().Warn("Received second signal, forcing shutdown", "signal", sig)
+		return errors.Join(c.stillRunning(fmt.Errorf("%w: received %v", ErrForcedShutdown, sig)), errs())
+	case <-timeout:
+		c.logger().Warn("Services did not stop within grace period, forcing shutdown", "gracePeriod", gracePeriod)
+		return errors.Join(c.stillRunning(fmt.Errorf("%w: grace period of %v exceeded", ErrForcedShutdown, gracePeriod)), errs())
+	}
+}
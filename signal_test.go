@@ -0,0 +1,129 @@
+//go:build unix
+
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func sendSignal(t *testing.T, c *service.Container, sig syscall.Signal) {
+	require.Eventually(t, func() bool {
+		return c.RunningCount() > 0
+	}, time.Second, time.Millisecond)
+	require.NoError(t, syscall.Kill(syscall.Getpid(), sig))
+}
+
+func TestRunUntilSignal(t *testing.T) {
+	c := service.NewContainer()
+	service.New("db").
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return errors.New("connection closed")
+		}).
+		Register(c)
+
+	go sendSignal(t, c, syscall.SIGUSR1)
+	err := c.RunUntilSignal(context.Background(), syscall.SIGUSR1)
+	assert.EqualError(t, err, "failed to run service db: connection closed")
+	assert.Equal(t, 0, c.RunningCount())
+}
+
+func TestRunUntilSignalForced(t *testing.T) {
+	c := service.NewContainer()
+	stuck := make(chan struct{})
+	defer close(stuck)
+	service.New("stuck").
+		Run(func(ctx context.Context) error {
+			<-stuck
+			return nil
+		}).
+		Register(c)
+
+	go func() {
+		sendSignal(t, c, syscall.SIGUSR1)
+		require.Eventually(t, func() bool {
+			state, _ := c.ServiceState("stuck")
+			return state == service.StateStopping
+		}, time.Second, time.Millisecond)
+		require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	}()
+	err := c.RunUntilSignal(context.Background(), syscall.SIGUSR1)
+	assert.ErrorIs(t, err, service.ErrForcedShutdown)
+}
+
+func TestRunUntilSignalGracePeriod(t *testing.T) {
+	c := service.NewContainer()
+	c.SetShutdownGracePeriod(20 * time.Millisecond)
+	stuck := make(chan struct{})
+	defer close(stuck)
+	service.New("stuck").
+		Run(func(ctx context.Context) error {
+			<-stuck
+			return nil
+		}).
+		Register(c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		require.Eventually(t, func() bool {
+			return c.RunningCount() > 0
+		}, time.Second, time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	err := c.RunUntilSignal(ctx, syscall.SIGUSR1)
+	assert.ErrorIs(t, err, service.ErrForcedShutdown)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestRunUntilSignalDuringInit(t *testing.T) {
+	c := service.NewContainer()
+	initStarted := make(chan struct{}, 10)
+	service.New("db").
+		Init(func(ctx context.Context) error {
+			initStarted <- struct{}{}
+			return errors.New("connection refused")
+		}).
+		InitRetry(service.InitRetryPolicy{Backoff: service.Backoff{Initial: 100 * time.Millisecond}, MaxElapsed: 5 * time.Second}).
+		Register(c)
+
+	go func() {
+		<-initStarted
+		require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	}()
+	start := time.Now()
+	err := c.RunUntilSignal(context.Background(), syscall.SIGUSR1)
+	assert.EqualError(t, err, "failed to init service db: connection refused")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRunUntilSignalContextDone(t *testing.T) {
+	c := service.NewContainer()
+	c.Register(blockingService("db"))
+	shutdown := make(chan struct{})
+	c.OnShutdown(func() {
+		close(shutdown)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		require.Eventually(t, func() bool {
+			return c.RunningCount() > 0
+		}, time.Second, time.Millisecond)
+		cancel()
+	}()
+	err := c.RunUntilSignal(ctx, syscall.SIGUSR1)
+	require.NoError(t, err)
+	select {
+	case <-shutdown:
+	default:
+		t.Fatal("OnShutdown was not called")
+	}
+}
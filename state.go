@@ -0,0 +1,75 @@
+package service
+
+import (
+	"slices"
+	"time"
+)
+
+// State of a single service inside the container
+type State int
+
+const (
+	// StateRegistered services are known to the container, but not initialized yet
+	StateRegistered State = iota
+	StateInitializing
+	StateInitialized
+	StateRunning
+	StateStopping
+	StateStopped
+	StateFailed
+	// StateRestarting services wait for their next restart, see RestartPolicy
+	StateRestarting
+)
+
+func (s State) String() string {
+	switch s {
+	case StateRegistered:
+		return "registered"
+	case StateInitializing:
+		return "initializing"
+	case StateInitialized:
+		return "initialized"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	case StateRestarting:
+		return "restarting"
+	}
+	return "unknown"
+}
+
+// transitions contains all valid state changes of a service
+var transitions = map[State][]State{
+	StateRegistered:   {StateInitializing},
+	StateInitializing: {StateInitialized, StateFailed},
+	StateInitialized:  {StateRunning, StateStopped, StateFailed},
+	StateRunning:      {StateStopping, StateStopped, StateFailed, StateRestarting},
+	StateStopping:     {StateStopped, StateFailed},
+	StateRestarting:   {StateRunning, StateStopping, StateStopped},
+}
+
+// setState changes the state of a service, invalid transitions are logged and ignored.
+// Must be called with Container.mu held.
+func (c *Container) setState(rc *runContext, to State) {
+	if !slices.Contains(transitions[rc.state], to) {
+		c.logger().Error("Invalid service state transition", "name", rc.service.name, "from", rc.state, "to", to)
+		return
+	}
+	rc.state = to
+	rc.since[to] = time.Now()
+}
+
+// ServiceState returns the current state of a registered service
+func (c *Container) ServiceState(name string) (State, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if rc, ok := c.runContexts[name]; ok {
+		return rc.state, true
+	}
+	return StateRegistered, c.lookup(name) != nil
+}
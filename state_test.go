@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServiceState(t *testing.T) {
+	c := service.NewContainer()
+	s1 := &testService{Name: "s1"}
+	s2 := &testService{Name: "s2", ErrorDuringRun: errors.New("failed")}
+	c.Register(s1)
+	c.Register(s2, service.Critical(false))
+
+	state, ok := c.ServiceState(s1.String())
+	assert.True(t, ok)
+	assert.Equal(t, service.StateRegistered, state)
+	_, ok = c.ServiceState("unknown")
+	assert.False(t, ok)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	<-s1.startedCh
+	assert.Eventually(t, func() bool {
+		state, _ := c.ServiceState(s2.String())
+		return state == service.StateFailed
+	}, time.Second, time.Millisecond)
+	state, _ = c.ServiceState(s1.String())
+	assert.Equal(t, service.StateRunning, state)
+
+	c.StopAll()
+	c.WaitAllStopped()
+	state, _ = c.ServiceState(s1.String())
+	assert.Equal(t, service.StateStopped, state)
+}
+
+// blockingService runs until it is stopped and can be started multiple times
+type blockingService string
+
+func (s blockingService) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (s blockingService) String() string {
+	return string(s)
+}
+
+// Calls all public methods concurrently to be checked with "go test -race"
+func TestConcurrentAccess(t *testing.T) {
+	c := service.NewContainer()
+	c.SetHealthInterval(time.Millisecond)
+	for i := 0; i < 5; i++ {
+		c.Register(&testService{Name: fmt.Sprintf("s%d", i)})
+	}
+	service.New("flaky").
+		Restart(service.RestartPolicy{Mode: service.RestartAlways, Backoff: fastBackoff}).
+		Run(func(ctx context.Context) error {
+			return errors.New("flaky")
+		}).
+		Register(c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := sync.WaitGroup{}
+	readers := []func(){
+		func() { c.RunningCount() },
+		func() { c.ServiceNames() },
+		func() { c.ServiceErrors() },
+		func() { c.RestartCounts() },
+		func() { c.Health() },
+		func() { c.ServiceState("flaky") },
+		func() { c.SetStopTimeout(time.Second) },
+		func() { _ = service.Default() },
+	}
+	for _, read := range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				read()
+				time.Sleep(100 * time.Microsecond)
+			}
+		}()
+	}
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("tenant-%d", i)
+		require.NoError(t, c.Add(context.Background(), blockingService(name)))
+		require.NoError(t, c.RestartService(context.Background(), name))
+		require.NoError(t, c.Remove(context.Background(), name))
+	}
+	c.StopAll()
+	c.WaitAllStopped()
+
+	cancel()
+	wg.Wait()
+	assert.Equal(t, 0, c.RunningCount())
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// ServiceStatus is a snapshot of the lifecycle of a single service
+type ServiceStatus struct {
+	Name  string `json:"name"`
+	State State  `json:"state"`
+	// Critical is false for services registered with Critical(false)
+	Critical bool `json:"critical"`
+	// Since contains the time each state was entered the last time
+	Since map[State]time.Time `json:"since"`
+	// InitDuration is the time Init took, marshaled as duration string, e.g. "1.5s"
+	InitDuration time.Duration `json:"-"`
+	// Uptime is the time since the service is running, zero if it is not running
+	Uptime time.Duration `json:"-"`
+	// StopDuration is the time the last stop took, zero if the service was not stopped yet
+	StopDuration time.Duration `json:"-"`
+	// LastError occurred during Init, Run, Stop or Close of the service
+	LastError error `json:"-"`
+	Restarts  int   `json:"restarts"`
+	// Failures counts all errors returned by Init, Run, Stop and Close
+	Failures int `json:"failures"`
+}
+
+func (s ServiceStatus) MarshalJSON() ([]byte, error) {
+	type status ServiceStatus
+	var lastError string
+	if s.LastError != nil {
+		lastError = s.LastError.Error()
+	}
+	return json.Marshal(struct {
+		status
+		InitDuration string `json:"initDuration"`
+		Uptime       string `json:"uptime"`
+		StopDuration string `json:"stopDuration"`
+		LastError    string `json:"lastError,omitempty"`
+	}{
+		status:       status(s),
+		InitDuration: s.InitDuration.String(),
+		Uptime:       s.Uptime.String(),
+		StopDuration: s.StopDuration.String(),
+		LastError:    lastError,
+	})
+}
+
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *State) UnmarshalText(text []byte) error {
+	for state := StateRegistered; state <= StateRestarting; state++ {
+		if state.String() == string(text) {
+			*s = state
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown service state '%s'", text)
+}
+
+// Status returns the status of all registered services in order of registration
+func (c *Container) Status() []ServiceStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	status := make([]ServiceStatus, 0, len(c.services))
+	for _, s := range c.services {
+		st := ServiceStatus{
+			Name:     s.name,
+			State:    StateRegistered,
+			Critical: !s.optional,
+			Since:    map[State]time.Time{StateRegistered: s.registeredAt},
+		}
+		if rc, ok := c.runContexts[s.name]; ok {
+			st.State = rc.state
+			maps.Copy(st.Since, rc.since)
+			st.InitDuration = rc.initDuration
+			if rc.state == StateRunning {
+				st.Uptime = now.Sub(rc.since[StateRunning])
+			}
+			st.StopDuration = rc.stopDuration
+			st.LastError = errors.Join(rc.initErr, rc.err, rc.stopErr, rc.closeErr)
+			st.Restarts = rc.restarts
+			st.Failures = rc.failures
+		}
+		status = append(status, st)
+	}
+	return status
+}
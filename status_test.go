@@ -0,0 +1,80 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStatus(t *testing.T) {
+	c := service.NewContainer()
+	service.New("db").
+		Init(func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}).
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Register(c)
+	service.New("metrics").
+		Critical(false).
+		Run(func(ctx context.Context) error {
+			return errors.New("push failed")
+		}).
+		Register(c)
+	service.New("api").DependsOn("db").Register(c)
+
+	status := c.Status()
+	require.Len(t, status, 3)
+	assert.Equal(t, service.StateRegistered, status[0].State)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return c.Status()[1].State == service.StateFailed
+	}, time.Second, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	status = c.Status()
+	assert.Equal(t, []string{"db", "metrics", "api"}, []string{status[0].Name, status[1].Name, status[2].Name})
+
+	db := status[0]
+	assert.Equal(t, service.StateRunning, db.State)
+	assert.True(t, db.Critical)
+	assert.GreaterOrEqual(t, db.InitDuration, 10*time.Millisecond)
+	assert.Greater(t, db.Uptime, time.Duration(0))
+	assert.NoError(t, db.LastError)
+	for _, state := range []service.State{service.StateRegistered, service.StateInitializing, service.StateInitialized, service.StateRunning} {
+		assert.Contains(t, db.Since, state)
+	}
+
+	metrics := status[1]
+	assert.False(t, metrics.Critical)
+	assert.EqualError(t, metrics.LastError, "push failed")
+	assert.Equal(t, time.Duration(0), metrics.Uptime)
+
+	data, err := json.Marshal(metrics)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "metrics", decoded["name"])
+	assert.Equal(t, "failed", decoded["state"])
+	assert.Equal(t, "push failed", decoded["lastError"])
+	assert.Equal(t, "0s", decoded["uptime"])
+	assert.Contains(t, decoded["since"], "running")
+
+	// The api completed without error
+	assert.Equal(t, service.StateStopped, status[2].State)
+
+	c.StopAll()
+	c.WaitAllStopped()
+	assert.Equal(t, service.StateStopped, c.Status()[0].State)
+	assert.Equal(t, []string{"db", "metrics", "api"}, c.ServiceNames())
+}
@@ -0,0 +1,67 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStopperIsCalled(t *testing.T) {
+	c := service.NewContainer()
+	stopErr := errors.New("stop failed")
+	stopCh := make(chan struct{})
+
+	service.New("server").
+		Run(func(ctx context.Context) error {
+			// Run only returns after Stop was called
+			<-stopCh
+			return nil
+		}).
+		Stop(func(ctx context.Context) error {
+			close(stopCh)
+			return stopErr
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.StopAll()
+	c.WaitAllStopped()
+
+	errs := c.ServiceErrors()
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs["server"], stopErr)
+}
+
+func TestStopTimeoutReleasesDependencies(t *testing.T) {
+	c := service.NewContainer()
+	log := &eventLog{}
+	hang := make(chan struct{})
+	defer close(hang)
+
+	loggingService(log, "db").Register(c)
+	service.New("api").
+		DependsOn("db").
+		StopTimeout(50 * time.Millisecond).
+		Run(func(ctx context.Context) error {
+			<-hang
+			return nil
+		}).
+		Stop(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}).
+		Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	c.StopAll()
+	c.WaitAllStoppedTimeout(time.Second)
+
+	assert.Equal(t, []string{"init db", "stop db"}, log.get())
+	assert.ErrorIs(t, c.ServiceErrors()["api"], context.DeadlineExceeded)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+var _ Runner = &Container{}
+
+// Strategy defines which services are stopped when a single service fails
+type Strategy int
+
+const (
+	// OneForAll stops all services of the container when one fails, which is the default
+	OneForAll Strategy = iota
+	// OneForOne only stops the failed service, all other services keep running
+	OneForOne
+	// RestForOne restarts all services registered after a service that is restarted by its RestartPolicy.
+	// A failed service that is not restarted stops all services, like OneForAll.
+	RestForOne
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case OneForAll:
+		return "one-for-all"
+	case OneForOne:
+		return "one-for-one"
+	case RestForOne:
+		return "rest-for-one"
+	}
+	return "unknown"
+}
+
+// SetStrategy defines how the container reacts when a service fails and is not restarted.
+// Combine nested containers with restart policies to build supervision trees.
+func (c *Container) SetStrategy(strategy Strategy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.strategy = strategy
+}
+
+// SetName sets the name of the container, used as service name when registered in another container
+func (c *Container) SetName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.name = name
+}
+
+func (c *Container) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.name == "" {
+		return "container"
+	}
+	return c.name
+}
+
+// Run starts all services and blocks until all services are stopped.
+// It returns the errors of all failed services joined, each error is a *ServiceError with the name and phase of the service.
+// This allows to register a Container as service inside another container.
+// Run can be called again after it returned, e.g. when the container is restarted by its parent.
+// Run returns an error when the container is still running, e.g. after StartAll.
+func (c *Container) Run(ctx context.Context) error {
+	if err := c.reset(); err != nil {
+		return err
+	}
+	if err := c.StartAll(ctx); err != nil {
+		c.WaitAllStopped()
+		return errors.Join(err, c.shutdownErrors())
+	}
+	c.WaitAllStopped()
+	// Services that returned on their own are closed as well
+	c.StopAll()
+	c.WaitAllStopped()
+	return c.runErrors()
+}
+
+// runErrors returns the init, run, stop and close errors of all services in order of their dependencies joined
+func (c *Container) runErrors() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for _, s := range c.order {
+		if rc, ok := c.runContexts[s.name]; ok {
+			errs = append(errs, rc.initErr)
+			if rc.err != nil {
+				errs = append(errs, &ServiceError{Service: s.name, Phase: PhaseRun, Err: rc.err})
+			}
+			errs = append(errs, rc.stopErr, rc.closeErr)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reset prepares a stopped container to be started again.
+// It returns an error when the previous run is not stopped and closed yet.
+func (c *Container) reset() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.runCtx == nil {
+		return nil
+	}
+	select {
+	case <-c.closed:
+	default:
+		return errors.New("container is still running")
+	}
+	c.runCtx = nil
+	c.runCtxCancel = nil
+	c.order = nil
+	c.runContexts = map[string]*runContext{}
+	c.callOnStopAllOnce = nil
+	c.initOrder = nil
+	c.closed = nil
+	return nil
+}
+
+// fail reports the failure of a service that is not caused by Run, e.g. a failed Ready
+func (c *Container) fail(rc *runContext, d time.Duration, err error) {
+	c.onFailed(LifecycleEvent{Service: rc.service.name, Phase: PhaseRun, Duration: d, Err: err})
+	c.handleFailure(rc, err)
+}
+
+// handleFailure applies the container strategy, failures of optional services are only logged
+func (c *Container) handleFailure(rc *runContext, err error) {
+	if rc.service.optional {
+		c.logger().Warn("Optional service failed, other services keep running", "name", rc.service.name, "error", err)
+		return
+	}
+	c.onFailure(rc)
+}
+
+// onFailure applies the container strategy after a service failed and is not restarted
+func (c *Container) onFailure(failed *runContext) {
+	c.mu.Lock()
+	strategy := c.strategy
+	c.mu.Unlock()
+	switch strategy {
+	case OneForOne:
+		c.logger().Warn("Service failed, other services keep running", "name", failed.service.name)
+	default:
+		// Run returns, a parent container can restart this container according to its RestartPolicy
+		c.StopAll()
+	}
+}
+
+// stopRest stops all running services registered after the restarting service in reverse order of registration.
+// It returns the stopped services, which must be started again with startRest. Only used with RestForOne.
+func (c *Container) stopRest(restarting *runContext) []*runContext {
+	c.mu.Lock()
+	if c.strategy != RestForOne {
+		c.mu.Unlock()
+		return nil
+	}
+	var rest []*runContext
+	after := false
+	for _, s := range c.services {
+		if s.name == restarting.service.name {
+			after = true
+			continue
+		}
+		if rc, ok := c.runContexts[s.name]; ok && after && !rc.isDone() {
+			rest = append(rest, rc)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(rest) > 0 {
+		c.logger().Warn("Service restarts, stopping services registered after it", "name", restarting.service.name, "count", len(rest))
+	}
+	for i := len(rest) - 1; i >= 0; i-- {
+		c.stopOne(context.Background(), rest[i])
+	}
+	return rest
+}
+
+// startRest starts the services stopped by stopRest again in order of their dependencies
+func (c *Container) startRest(rest []*runContext) {
+	c.mu.Lock()
+	var names []string
+	for _, s := range c.order {
+		if slices.ContainsFunc(rest, func(rc *runContext) bool { return rc.service == s }) {
+			names = append(names, s.name)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, name := range names {
+		if err := c.StartService(context.Background(), name); err != nil {
+			c.logger().Error("Failed to restart service", "name", name, "error", err)
+		}
+	}
+}
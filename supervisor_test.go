@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOneForOne(t *testing.T) {
+	c := service.NewContainer()
+	c.SetStrategy(service.OneForOne)
+	s1 := &testService{Name: "s1"}
+	s2 := &testService{Name: "s2", ErrorDuringRun: fmt.Errorf("service failed during run")}
+	s3 := &testService{Name: "s3"}
+	c.Register(s1)
+	c.Register(s2)
+	c.Register(s3)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	<-s1.startedCh
+	<-s3.startedCh
+	assert.Eventually(t, func() bool {
+		return len(c.ServiceErrors()) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 2, c.RunningCount())
+
+	c.StopAll()
+	c.WaitAllStopped()
+	assertServiceStartedAndStopped(t, s1)
+	assertServiceStartedAndStopped(t, s3)
+}
+
+func TestRestForOne(t *testing.T) {
+	c := service.NewContainer()
+	c.SetStrategy(service.RestForOne)
+	log := &eventLog{}
+	loggingService(log, "s1").Register(c)
+	var runs atomic.Int32
+	service.New("s2").
+		Restart(service.RestartPolicy{Mode: service.RestartOnFailure, Backoff: fastBackoff}).
+		Run(func(ctx context.Context) error {
+			if runs.Add(1) == 1 {
+				return errors.New("service failed during run")
+			}
+			<-ctx.Done()
+			return nil
+		}).
+		Register(c)
+	loggingService(log, "s3").Register(c)
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	// s3 is restarted together with s2, s1 keeps running
+	assert.Eventually(t, func() bool {
+		return runs.Load() == 2 && c.RunningCount() == 3 && len(log.get()) == 3
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"init s1", "init s3", "stop s3"}, log.get())
+
+	c.StopAll()
+	c.WaitAllStopped()
+	assert.Len(t, c.ServiceErrors(), 1)
+}
+
+// A failed service that is not restarted stops the container, so a parent container can restart it
+func TestRestForOneNotRestarted(t *testing.T) {
+	c := service.NewContainer()
+	c.SetStrategy(service.RestForOne)
+	c.Register(blockingService("s1"))
+	service.New("s2").Run(func(ctx context.Context) error {
+		return errors.New("service failed during run")
+	}).Register(c)
+	c.Register(blockingService("s3"))
+
+	err := c.Run(context.Background())
+	assert.EqualError(t, err, "failed to run service s2: service failed during run")
+	assert.Equal(t, 0, c.RunningCount())
+}
+
+// A failing child container is stopped as a whole, while its sibling keeps running
+func TestNestedContainer(t *testing.T) {
+	child := service.NewContainer()
+	child.SetName("ingestion")
+	w1 := &testService{Name: "w1"}
+	w2 := &testService{Name: "w2", ErrorDuringRun: fmt.Errorf("worker failed")}
+	child.Register(w1)
+	child.Register(w2)
+
+	parent := service.NewContainer()
+	parent.SetStrategy(service.OneForOne)
+	api := &testService{Name: "api"}
+	parent.Register(api)
+	parent.Register(child)
+
+	err := parent.StartAll(context.Background())
+	require.NoError(t, err)
+	<-api.startedCh
+	assert.Eventually(t, func() bool {
+		return len(parent.ServiceErrors()) == 1
+	}, time.Second, time.Millisecond)
+	require.Error(t, parent.ServiceErrors()["ingestion"])
+	assert.Contains(t, parent.ServiceErrors()["ingestion"].Error(), "worker failed")
+	assertServiceStartedAndStopped(t, w1)
+	assertServiceStillRunning(t, api)
+
+	parent.StopAll()
+	parent.WaitAllStopped()
+	assertServiceStartedAndStopped(t, api)
+}
+
+// A child container with restart policy is started again as a whole
+func TestNestedContainerRestart(t *testing.T) {
+	var runs atomic.Int32
+	child := service.NewContainer()
+	child.SetName("child")
+	service.New("worker").Run(func(ctx context.Context) error {
+		if runs.Add(1) < 3 {
+			return errors.New("worker failed")
+		}
+		<-ctx.Done()
+		return nil
+	}).Register(child)
+
+	parent := service.NewContainer()
+	parent.Register(child, service.Restart(service.RestartPolicy{Mode: service.RestartOnFailure, Backoff: fastBackoff}))
+
+	err := parent.StartAll(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return runs.Load() == 3
+	}, time.Second, time.Millisecond)
+
+	parent.StopAll()
+	parent.WaitAllStopped()
+	assert.Equal(t, 2, parent.RestartCounts()["child"])
+}
+
+func TestRunWithFailedInit(t *testing.T) {
+	c := service.NewContainer()
+	service.New("db").
+		Run(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		Close(func(ctx context.Context) error {
+			return errors.New("flush failed")
+		}).
+		Register(c)
+	service.New("api").
+		Init(func(ctx context.Context) error {
+			return errors.New("port in use")
+		}).
+		DependsOn("db").
+		Register(c)
+
+	err := c.Run(context.Background())
+	assert.EqualError(t, err, "failed to init service api: port in use\nfailed to close service db: flush failed")
+
+	err = c.Wait(context.Background())
+	assert.EqualError(t, err, "failed to close service db: flush failed\nfailed to init service api: port in use")
+	assert.EqualError(t, c.ServiceErrors()["api"], "failed to init service api: port in use")
+}
+
+func TestRunWhileRunning(t *testing.T) {
+	c := service.NewContainer()
+	c.Register(blockingService("db"))
+
+	err := c.StartAll(context.Background())
+	require.NoError(t, err)
+	err = c.Run(context.Background())
+	assert.EqualError(t, err, "container is still running")
+	assert.Equal(t, 1, c.RunningCount())
+
+	c.StopAll()
+	c.WaitAllStopped()
+}
@@ -0,0 +1,49 @@
+package service
+
+import "context"
+
+// Tracer opens spans for the lifecycle phases of the container and its services,
+// e.g. to show slow startups as trace waterfall. Implement it to bridge to a tracing library like OpenTelemetry.
+//
+// Spans are opened for StartAll and StopAll of the container, and for each Init and Run of a service.
+// The context returned by StartSpan is passed to Init and Run, so spans of services become children of the StartAll span.
+type Tracer interface {
+	// StartSpan opens a span with the given name, service is empty for spans of the container
+	StartSpan(ctx context.Context, name string, service string) (context.Context, Span)
+}
+
+// Span is an open span of a Tracer
+type Span interface {
+	// End closes the span, err is set when the phase failed
+	End(err error)
+}
+
+// Span names passed to Tracer.StartSpan
+const (
+	SpanStartAll = "service.StartAll"
+	SpanStopAll  = "service.StopAll"
+	SpanInit     = "service.Init"
+	SpanRun      = "service.Run"
+)
+
+type noopSpan struct{}
+
+func (noopSpan) End(error) {}
+
+// SetTracer sets the Tracer used for all lifecycle phases, by default no spans are recorded
+func (c *Container) SetTracer(tracer Tracer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tracer = tracer
+}
+
+// startSpan opens a span with the configured tracer, if any
+func (c *Container) startSpan(ctx context.Context, name string, service string) (context.Context, Span) {
+	c.mu.Lock()
+	tracer := c.tracer
+	c.mu.Unlock()
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return tracer.StartSpan(ctx, name, service)
+}
@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/niondir/go-service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+type spanKey struct{}
+
+type recordedSpan struct {
+	name    string
+	service string
+	parent  string
+	ended   bool
+	err     error
+}
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) StartSpan(ctx context.Context, name string, service string) (context.Context, service.Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &recordedSpan{name: name, service: service}
+	if parent, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		span.parent = parent.name
+	}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), &tracedSpan{tracer: t, span: span}
+}
+
+func (t *recordingTracer) get() []recordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var spans []recordedSpan
+	for _, s := range t.spans {
+		spans = append(spans, *s)
+	}
+	return spans
+}
+
+type tracedSpan struct {
+	tracer *recordingTracer
+	span   *recordedSpan
+}
+
+func (s *tracedSpan) End(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.span.ended = true
+	s.span.err = err
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &recordingTracer{}
+	c := service.NewContainer()
+	c.SetTracer(tracer)
+	runErr := errors.New("connection lost")
+	var runSpan string
+	service.New("db").
+		Init(func(ctx context.Context) error {
+			return nil
+		}).
+		Run(func(ctx context.Context) error {
+			runSpan = ctx.Value(spanKey{}).(*recordedSpan).name
+			return runErr
+		}).
+		Register(c)
+
+	err := c.Run(context.Background())
+	require.ErrorIs(t, err, runErr)
+	assert.Equal(t, service.SpanRun, runSpan)
+
+	assert.Equal(t, []recordedSpan{
+		{name: service.SpanStartAll, ended: true},
+		{name: service.SpanInit, service: "db", parent: service.SpanStartAll, ended: true},
+		{name: service.SpanRun, service: "db", parent: service.SpanStartAll, ended: true, err: runErr},
+		{name: service.SpanStopAll, parent: service.SpanStartAll, ended: true},
+	}, tracer.get())
+}